@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
+	// Embedded so tz parameters resolve in the Alpine image, which ships no zoneinfo.
+	_ "time/tzdata"
 
 	"gh-stats/backend/internal/api"
 	"gh-stats/backend/internal/cache"
 	"gh-stats/backend/internal/github"
+	"gh-stats/backend/internal/history"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -28,7 +38,14 @@ func main() {
 		frontendURL = "http://localhost:3000"
 	}
 
-	store := cache.New()
+	limits, err := cacheLimits(os.Getenv("CACHE_MAX_ENTRIES"), os.Getenv("CACHE_MAX_MB"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := newStore(os.Getenv("CACHE_BACKEND"), os.Getenv("CACHE_PATH"), limits)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	var oauth *github.OAuthConfig
 	if clientID != "" && clientSecret != "" {
@@ -51,6 +68,37 @@ func main() {
 
 	handler := api.NewHandler(store, oauth, frontendURL, githubToken)
 
+	historyStore, err := newHistoryStore(os.Getenv("HISTORY_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler.SetHistory(historyStore)
+	tracker, err := newTracker(os.Getenv("HISTORY_USERS"), os.Getenv("HISTORY_IDLE_DAYS"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler.SetTracker(tracker)
+	rankHistory, err := newRankHistory(os.Getenv("RANK_HISTORY_DIR"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler.SetRankHistory(rankHistory)
+	rankingSource, err := newRankingSource(os.Getenv("RANKING_SOURCE"), os.Getenv("RANKING_DIR"), githubToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if rankingSource != nil {
+		handler.SetRankingSource(rankingSource)
+	}
+	snapshotInterval, err := snapshotInterval(os.Getenv("SNAPSHOT_INTERVAL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	preloadRankings, err := parseFlag("RANKING_PRELOAD", os.Getenv("RANKING_PRELOAD"), true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
@@ -67,15 +115,33 @@ func main() {
 	r.Get("/api/users/{username}/repos/{repo}", handler.GetUserRepoStats)
 	r.Get("/api/users/{username}/fun", handler.GetUserFunStats)
 	r.Get("/api/users/{username}/contributions", handler.GetUserContributions)
+	r.Get("/api/users/{username}/contributions/lifetime", handler.GetUserLifetimeContributions)
 	r.Get("/api/users/{username}/repo-commits", handler.GetUserRepoCommits)
+	r.Get("/api/users/{username}/pulls", handler.GetUserPullStats)
 	r.Get("/api/users/{username}/followers", handler.GetUserFollowers)
 	r.Get("/api/users/{username}/following", handler.GetUserFollowing)
 	r.Get("/api/users/{username}/code-frequency", handler.GetUserCodeFrequency)
+	r.Get("/api/users/{username}/languages/trends", handler.GetUserLanguageTrends)
+	r.Get("/api/users/{username}/history", handler.GetUserHistory)
+
+	r.Get("/api/cards/{username}/streak.svg", handler.GetStreakCard)
+	r.Get("/api/cards/{username}/languages.svg", handler.GetLanguagesCard)
+	r.Get("/api/cards/{username}/contributions.svg", handler.GetContributionsCard)
+	r.Get("/api/cards/{username}/fun.svg", handler.GetFunCard)
+
+	r.Get("/api/og/{username}.png", handler.GetOGImage)
+
+	r.Get("/api/compare", handler.GetComparison)
+	r.Get("/api/orgs/{org}/stats", handler.GetOrgStats)
 
 	r.Get("/api/rankings/countries", handler.GetAvailableCountries)
 	r.Get("/api/rankings/global", handler.GetGlobalRanking)
 	r.Get("/api/rankings/country/{country}", handler.GetCountryRanking)
+	r.Get("/api/rankings/country/{country}/movers", handler.GetCountryMovers)
 	r.Get("/api/rankings/user/{username}", handler.GetUserRanking)
+	r.Get("/api/rankings/status", handler.GetRankingStatus)
+
+	r.Get("/api/ratelimit", handler.GetRateLimits)
 
 	r.Get("/health", handler.Health)
 
@@ -84,10 +150,180 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on :%s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatal(err)
+	server := &http.Server{Addr: ":" + port, Handler: r}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handler.StartSnapshots(ctx, snapshotInterval)
+	if preloadRankings {
+		handler.StartRankingPreload(ctx)
+	}
+
+	go func() {
+		log.Printf("Server starting on :%s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		log.Printf("Failed to persist cache: %v", err)
+	}
+	if err := historyStore.Close(); err != nil {
+		log.Printf("Failed to close history: %v", err)
+	}
+}
+
+// newStore selects the cache backend: "memory" (default) or "file", which
+// persists to path so deploys keep sessions and cached stats.
+func newStore(backend, path string, limits cache.Limits) (cache.Backend, error) {
+	switch backend {
+	case "", "memory":
+		log.Println("Cache backend: memory")
+		return cache.NewWithLimits(limits), nil
+	case "file":
+		if path == "" {
+			path = "data/cache.gob"
+		}
+		log.Printf("Cache backend: file (%s)", path)
+		return cache.NewFileStore(path, limits)
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q (expected memory or file)", backend)
+	}
+}
+
+// newHistoryStore opens the snapshot log at path, data/history.jsonl by
+// default. "memory" keeps snapshots only until restart.
+func newHistoryStore(path string) (history.Store, error) {
+	switch path {
+	case "memory":
+		log.Println("History: memory")
+		return history.NewMemoryStore(), nil
+	case "":
+		path = "data/history.jsonl"
+	}
+	log.Printf("History: file (%s)", path)
+	return history.NewFileStore(path)
+}
+
+// newTracker tracks the comma-separated users, which are snapshotted until
+// nobody has viewed them for idleDays (30 by default).
+func newTracker(users, idleDays string) (*history.Tracker, error) {
+	idle := history.DefaultIdle
+	if idleDays != "" {
+		n, err := strconv.Atoi(idleDays)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid HISTORY_IDLE_DAYS %q", idleDays)
+		}
+		idle = time.Duration(n) * 24 * time.Hour
+	}
+	tracker := history.NewTracker(idle)
+	for _, username := range strings.Split(users, ",") {
+		if username = strings.TrimSpace(username); username == "" {
+			continue
+		}
+		if err := tracker.Track(username); err != nil {
+			return nil, fmt.Errorf("invalid HISTORY_USERS: %w", err)
+		}
+	}
+	log.Printf("History: tracking %d users", len(tracker.Active()))
+	return tracker, nil
+}
+
+// newRankHistory opens the directory of dated country rankings,
+// data/rankings by default. "memory" keeps them only until restart.
+func newRankHistory(dir string) (github.RankHistory, error) {
+	switch dir {
+	case "memory":
+		log.Println("Rank history: memory")
+		return github.NewMemoryRankHistory(), nil
+	case "":
+		dir = "data/rankings"
+	}
+	log.Printf("Rank history: file (%s)", dir)
+	return github.NewFileRankHistory(dir)
+}
+
+// newRankingSource selects where country rankings come from: "remote"
+// (default, the top-github-users cache, returned as nil), "search", which
+// crawls GitHub user search with the token, or "local", which reads
+// <country>.json files from dir.
+func newRankingSource(kind, dir, token string) (github.RankingSource, error) {
+	switch kind {
+	case "", "remote":
+		log.Println("Ranking source: remote")
+		return nil, nil
+	case "search":
+		if token == "" {
+			return nil, errors.New("RANKING_SOURCE=search requires GITHUB_TOKEN")
+		}
+		log.Println("Ranking source: GitHub search")
+		return github.NewSearchSource(token), nil
+	case "local":
+		if dir == "" {
+			dir = "data/countries"
+		}
+		log.Printf("Ranking source: local (%s)", dir)
+		return github.NewLocalSource(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown RANKING_SOURCE %q (expected remote, search or local)", kind)
+	}
+}
+
+// snapshotInterval reads how often tracked users are snapshotted, daily by
+// default.
+func snapshotInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Minute {
+		return 0, fmt.Errorf("invalid SNAPSHOT_INTERVAL %q (expected a duration of at least 1m)", value)
+	}
+	return d, nil
+}
+
+// parseFlag reads a boolean setting, def when unset.
+func parseFlag(name, value string, def bool) (bool, error) {
+	if value == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return b, nil
+}
+
+// cacheLimits reads the user data budget. Unset values keep the defaults and
+// 0 disables a limit.
+func cacheLimits(maxEntries, maxMB string) (cache.Limits, error) {
+	limits := cache.DefaultLimits
+	if maxEntries != "" {
+		n, err := strconv.Atoi(maxEntries)
+		if err != nil || n < 0 {
+			return limits, fmt.Errorf("invalid CACHE_MAX_ENTRIES %q", maxEntries)
+		}
+		limits.MaxEntries = n
+	}
+	if maxMB != "" {
+		n, err := strconv.ParseInt(maxMB, 10, 64)
+		if err != nil || n < 0 {
+			return limits, fmt.Errorf("invalid CACHE_MAX_MB %q", maxMB)
+		}
+		limits.MaxBytes = n << 20
 	}
+	log.Printf("Cache limits: %d entries, %d MB", limits.MaxEntries, limits.MaxBytes>>20)
+	return limits, nil
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
@@ -107,6 +343,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Expose-Headers", "Age, X-Cache-Status, X-Cache-Refreshing")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -1,5 +1,10 @@
 module gh-stats/backend
 
-go 1.23
+go 1.23.0
 
 require github.com/go-chi/chi/v5 v5.2.0
+
+require (
+	golang.org/x/image v0.25.0
+	golang.org/x/text v0.23.0 // indirect
+)
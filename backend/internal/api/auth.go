@@ -60,7 +60,7 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := github.NewClient(token.AccessToken)
-	profile, err := client.GetProfile("")
+	profile, err := client.GetProfile(r.Context(), "")
 	if err != nil {
 		log.Printf("get profile error: %v", err)
 		http.Error(w, "failed to get user profile", http.StatusInternalServerError)
@@ -178,6 +178,13 @@ func (h *Handler) getClientForUser(r *http.Request, targetUsername string) *gith
 	if session != nil {
 		return github.NewClient(session.AccessToken)
 	}
+	return h.getPublicClient(targetUsername)
+}
+
+// getPublicClient is the client for requests without a session. The
+// GITHUB_TOKEN owner is fetched without the token so their private data
+// stays hidden.
+func (h *Handler) getPublicClient(targetUsername string) *github.Client {
 	if h.publicTokenOwner != "" && strings.EqualFold(h.publicTokenOwner, targetUsername) {
 		return github.NewPublicClient()
 	}
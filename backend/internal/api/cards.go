@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gh-stats/backend/internal/card"
+	"gh-stats/backend/internal/github"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Card responses are cached by GitHub's camo image proxy and browsers for
+// cache_seconds, bounded so READMEs neither hammer the API nor go stale for
+// days.
+const (
+	defaultCardCacheSeconds = 1800
+	maxCardCacheSeconds     = 86400
+)
+
+// cardRenderer draws a card from a user's cached stats. Its errors are
+// shown on an error card.
+type cardRenderer func(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, opts card.Options) ([]byte, error)
+
+// GetStreakCard renders the contribution streak card.
+func (h *Handler) GetStreakCard(w http.ResponseWriter, r *http.Request) {
+	h.serveCard(w, r, func(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, opts card.Options) ([]byte, error) {
+		loc, err := parseTimezone(r)
+		if err != nil {
+			return nil, err
+		}
+		streak := stats.Streak
+		if loc != nil {
+			streak = streakIn(stats, loc)
+		}
+		return card.Streak(streak, opts), nil
+	})
+}
+
+// GetLanguagesCard renders the language breakdown card, by repository count
+// or, with languageMode=bytes, by code size.
+func (h *Handler) GetLanguagesCard(w http.ResponseWriter, r *http.Request) {
+	h.serveCard(w, r, func(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, opts card.Options) ([]byte, error) {
+		languages := stats.Languages
+		switch r.URL.Query().Get("languageMode") {
+		case "", "repos":
+		case "bytes":
+			if stats.LanguagesByBytes != nil {
+				languages = stats.LanguagesByBytes
+			}
+		default:
+			return nil, errors.New("languageMode must be repos or bytes")
+		}
+		return card.Languages(languages, opts), nil
+	})
+}
+
+// GetContributionsCard renders the contribution calendar as a heatmap.
+func (h *Handler) GetContributionsCard(w http.ResponseWriter, r *http.Request) {
+	h.serveCard(w, r, func(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, opts card.Options) ([]byte, error) {
+		return card.Contributions(stats.Contributions, stats.Streak.TotalContributions, opts), nil
+	})
+}
+
+// GetFunCard renders commit habits, in the tz parameter's timezone or the
+// one inferred from the commits.
+func (h *Handler) GetFunCard(w http.ResponseWriter, r *http.Request) {
+	h.serveCard(w, r, func(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, opts card.Options) ([]byte, error) {
+		loc, err := parseTimezone(r)
+		if err != nil {
+			return nil, err
+		}
+		commits, err := h.loadCommits(ctx, client, username, cacheKey, stats, false)
+		if err != nil {
+			log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
+		}
+		if loc == nil {
+			loc = inferTimezone(commits)
+		}
+		return card.Fun(computeFunStats(localizeCommits(commits, loc), len(stats.Repositories)), opts), nil
+	})
+}
+
+// serveCard loads a user's public stats and writes the card render draws.
+// Cards only ever show public data: they are fetched by image proxies
+// without the viewer's session.
+func (h *Handler) serveCard(w http.ResponseWriter, r *http.Request, render cardRenderer) {
+	opts, cacheSeconds, err := parseCardOptions(r)
+	if err != nil {
+		writeCardError(w, err.Error(), opts)
+		return
+	}
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		writeCardError(w, "username required", opts)
+		return
+	}
+
+	client := h.getPublicClient(username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	repoOpts := github.RepoOptions{Visibility: "public"}
+	cacheKey := statsCacheKey(username, false, repoOpts)
+	stats, _, err := h.loadStats(ctx, client, username, repoOpts, cacheKey)
+	if err != nil {
+		log.Printf("get card stats error for %s: %v", username, err)
+		message := "Failed to fetch stats"
+		if errors.Is(err, github.ErrNotFound) {
+			message = "User not found"
+		}
+		writeCardError(w, message, opts)
+		return
+	}
+
+	svg, err := render(ctx, client, username, cacheKey, stats, opts)
+	if err != nil {
+		writeCardError(w, err.Error(), opts)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d",
+		cacheSeconds, cacheSeconds, maxCardCacheSeconds))
+	w.Write(svg)
+}
+
+// writeCardError renders an error card. It is sent with status 200 and
+// without caching: camo turns error statuses into broken images, while the
+// card tells README visitors what went wrong.
+func writeCardError(w http.ResponseWriter, message string, opts card.Options) {
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write(card.Error(message, opts))
+}
+
+// parseCardOptions reads the theme, width, hide, hide_border, langs_count
+// and cache_seconds parameters. The returned options are usable for an
+// error card even when a parameter is invalid.
+func parseCardOptions(r *http.Request) (card.Options, int, error) {
+	q := r.URL.Query()
+	theme, _ := card.LookupTheme(card.DefaultTheme)
+	opts := card.Options{Theme: theme, Hide: make(map[string]bool)}
+
+	if name := q.Get("theme"); name != "" {
+		t, ok := card.LookupTheme(name)
+		if !ok {
+			return opts, 0, fmt.Errorf("theme must be one of %s", strings.Join(card.ThemeNames(), ", "))
+		}
+		opts.Theme = t
+	}
+	for _, field := range strings.Split(q.Get("hide"), ",") {
+		if field = strings.TrimSpace(strings.ToLower(field)); field != "" {
+			opts.Hide[field] = true
+		}
+	}
+
+	var err error
+	if opts.HideBorder, err = parseBoolParam(r, "hide_border"); err != nil {
+		return opts, 0, err
+	}
+	if opts.Width, err = parseIntParam(r, "width", card.MinWidth, card.MaxWidth); err != nil {
+		return opts, 0, err
+	}
+	if opts.MaxItems, err = parseIntParam(r, "langs_count", 1, 20); err != nil {
+		return opts, 0, err
+	}
+	cacheSeconds, err := parseIntParam(r, "cache_seconds", defaultCardCacheSeconds, maxCardCacheSeconds)
+	if err != nil {
+		return opts, 0, err
+	}
+	if cacheSeconds == 0 {
+		cacheSeconds = defaultCardCacheSeconds
+	}
+	return opts, cacheSeconds, nil
+}
+
+// parseIntParam reads an optional integer query parameter within [lo, hi].
+// It returns 0 when the parameter is absent.
+func parseIntParam(r *http.Request, name string, lo, hi int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, lo, hi)
+	}
+	return n, nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler_GetStreakCard_ServesCacheableSVG(t *testing.T) {
+	handler := newTestHandler()
+	handler.store.SetStats("testuser:public", &github.Stats{
+		Profile: github.Profile{Login: "testuser"},
+		Streak:  github.StreakStats{CurrentStreak: 3, LongestStreak: 10, TotalContributions: 420},
+	})
+
+	r := chi.NewRouter()
+	r.Get("/api/cards/{username}/streak.svg", handler.GetStreakCard)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cards/testuser/streak.svg?theme=dark&cache_seconds=7200", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/svg+xml") {
+		t.Errorf("expected an SVG content type, got %q", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=7200") {
+		t.Errorf("expected max-age=7200, got %q", cc)
+	}
+	if !strings.Contains(w.Body.String(), ">420<") {
+		t.Errorf("expected the total in the card, got %s", w.Body.String())
+	}
+}
+
+func TestHandler_GetLanguagesCard_RendersErrorCardForBadOptions(t *testing.T) {
+	handler := newTestHandler()
+
+	r := chi.NewRouter()
+	r.Get("/api/cards/{username}/languages.svg", handler.GetLanguagesCard)
+	for _, query := range []string{"theme=neon", "width=10", "cache_seconds=60", "hide_border=maybe"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/cards/testuser/languages.svg?"+query, nil))
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Something went wrong") {
+			t.Errorf("%s: expected an error card, got %d %s", query, w.Code, w.Body.String())
+		}
+		if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "no-store") {
+			t.Errorf("%s: expected error cards not to be cached, got %q", query, cc)
+		}
+	}
+}
+
+func TestHandler_GetStreakCard_IgnoresViewerSession(t *testing.T) {
+	tokens := tokensUsedWithSession(t, "/api/cards/{username}/streak.svg", "/api/cards/testuser/streak.svg",
+		func(h *Handler) http.HandlerFunc { return h.GetStreakCard })
+
+	if len(tokens) == 0 {
+		t.Fatal("expected the card to fetch stats")
+	}
+	for _, token := range tokens {
+		if token != "Bearer server-token" {
+			t.Errorf("expected cards to be fetched with the server token, got %q", token)
+		}
+	}
+}
+
+// tokensUsedWithSession requests path as the signed-in owner of the profile
+// and returns the Authorization headers sent to GitHub.
+func tokensUsedWithSession(t *testing.T, pattern, path string, endpoint func(*Handler) http.HandlerFunc) []string {
+	t.Helper()
+	var mu sync.Mutex
+	var tokens []string
+	mockServer := setupMockGitHubServer(map[string]http.HandlerFunc{
+		"/": func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			tokens = append(tokens, r.Header.Get("Authorization"))
+			mu.Unlock()
+			http.NotFound(w, r)
+		},
+	})
+	defer mockServer.Close()
+	defer github.SetAPIURL(github.SetAPIURL(mockServer.URL))
+	defer github.SetGraphQLURL(github.SetGraphQLURL(mockServer.URL + "/graphql"))
+
+	handler := newTestHandler()
+	handler.publicClient = github.NewClient("server-token")
+	session := handler.store.CreateSession("testuser", "session-token", "")
+
+	r := chi.NewRouter()
+	r.Get(pattern, endpoint(handler))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session.ID})
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return tokens
+}
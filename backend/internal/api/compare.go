@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+// maxCompareUsers bounds the users compared in one request.
+const maxCompareUsers = 5
+
+// comparedProfile is what a comparison needs of one user.
+type comparedProfile struct {
+	login   string
+	stats   *github.Stats
+	commits []github.Commit
+}
+
+// GetComparison lines up the public stats of several users. Stats and
+// commits come from the same cache entries as the per-user endpoints, so
+// profiles that were already loaded are not fetched again.
+func (h *Handler) GetComparison(w http.ResponseWriter, r *http.Request) {
+	users, err := parseCompareUsers(r.URL.Query().Get("users"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commitFetchTimeout)
+	defer cancel()
+	session := h.getSession(r)
+
+	profiles := make([]comparedProfile, len(users))
+	errs := make([]error, len(users))
+	var wg sync.WaitGroup
+	for i, username := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := h.getClientForUser(r, username)
+			isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
+			opts := github.RepoOptions{Visibility: "public"}
+			cacheKey := statsCacheKey(username, isOwnProfile, opts)
+
+			stats, _, err := h.loadStats(ctx, client, username, opts, cacheKey)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			commits, err := h.loadCommits(ctx, client, username, cacheKey, stats, false)
+			if err != nil {
+				log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
+			}
+			profiles[i] = comparedProfile{login: username, stats: stats, commits: commits}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("compare error for %s: %v", users[i], err)
+			writeError(w, err, fmt.Sprintf("user %s not found", users[i]), "failed to fetch stats")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildComparison(profiles, loc))
+}
+
+// parseCompareUsers reads the comma-separated users parameter, dropping
+// duplicates.
+func parseCompareUsers(param string) ([]string, error) {
+	var users []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(param, ",") {
+		username := strings.TrimSpace(part)
+		if username == "" || seen[strings.ToLower(username)] {
+			continue
+		}
+		seen[strings.ToLower(username)] = true
+		users = append(users, username)
+	}
+	if len(users) < 2 || len(users) > maxCompareUsers {
+		return nil, fmt.Errorf("users must list 2 to %d usernames", maxCompareUsers)
+	}
+	return users, nil
+}
+
+// buildComparison assembles the comparison. Fun stats are computed in loc,
+// or in each user's inferred timezone when loc is nil.
+func buildComparison(profiles []comparedProfile, loc *time.Location) github.Comparison {
+	users := make([]github.ComparedUser, len(profiles))
+	calendars := make([][]github.ContributionWeek, len(profiles))
+	for i, p := range profiles {
+		userLoc := loc
+		if userLoc == nil {
+			userLoc = inferTimezone(p.commits)
+		}
+		streak := p.stats.Streak
+		if loc != nil {
+			streak = streakIn(p.stats, loc)
+		}
+		funStats := computeFunStats(localizeCommits(p.commits, userLoc), len(p.stats.Repositories))
+		funStats.Timezone = userLoc.String()
+
+		login := p.stats.Profile.Login
+		if login == "" {
+			login = p.login
+		}
+		users[i] = github.ComparedUser{
+			Login:     login,
+			Name:      p.stats.Profile.Name,
+			AvatarURL: p.stats.Profile.AvatarURL,
+			Streak:    streak,
+			Languages: p.stats.Languages,
+			FunStats:  funStats,
+		}
+		calendars[i] = p.stats.Contributions
+	}
+
+	return github.Comparison{
+		Users:    users,
+		Calendar: alignCalendars(calendars),
+		Deltas:   compareUsers(users),
+	}
+}
+
+// alignCalendars lines up contribution calendars on the union of their
+// dates, counting 0 where a calendar has no entry.
+func alignCalendars(calendars [][]github.ContributionWeek) github.ComparisonCalendar {
+	counts := make([]map[string]int, len(calendars))
+	dateSet := make(map[string]bool)
+	for i, weeks := range calendars {
+		counts[i] = make(map[string]int)
+		for _, week := range weeks {
+			for _, day := range week.Days {
+				counts[i][day.Date] = day.Count
+				dateSet[day.Date] = true
+			}
+		}
+	}
+
+	dates := make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	aligned := github.ComparisonCalendar{Dates: dates, Counts: make([][]int, len(calendars))}
+	for i := range calendars {
+		aligned.Counts[i] = make([]int, len(dates))
+		for j, date := range dates {
+			aligned.Counts[i][j] = counts[i][date]
+		}
+	}
+	return aligned
+}
+
+// compareUsers derives the leaders of each metric and pairwise deltas.
+func compareUsers(users []github.ComparedUser) github.ComparisonDeltas {
+	deltas := github.ComparisonDeltas{
+		LongestStreak:      leader(users, func(u github.ComparedUser) int { return u.Streak.LongestStreak }),
+		CurrentStreak:      leader(users, func(u github.ComparedUser) int { return u.Streak.CurrentStreak }),
+		TotalContributions: leader(users, func(u github.ComparedUser) int { return u.Streak.TotalContributions }),
+		SharedLanguages:    []string{},
+		Pairs:              []github.UserPairDelta{},
+	}
+
+	languages := make([]map[string]bool, len(users))
+	for i, u := range users {
+		languages[i] = make(map[string]bool, len(u.Languages))
+		for _, lang := range u.Languages {
+			languages[i][lang.Name] = true
+		}
+	}
+	if len(users) > 0 {
+		for lang := range languages[0] {
+			shared := true
+			for _, set := range languages[1:] {
+				shared = shared && set[lang]
+			}
+			if shared {
+				deltas.SharedLanguages = append(deltas.SharedLanguages, lang)
+			}
+		}
+		sort.Strings(deltas.SharedLanguages)
+	}
+
+	for i := range users {
+		for j := i + 1; j < len(users); j++ {
+			shared := []string{}
+			union := len(languages[j])
+			for lang := range languages[i] {
+				if languages[j][lang] {
+					shared = append(shared, lang)
+				} else {
+					union++
+				}
+			}
+			sort.Strings(shared)
+
+			overlap := 0.0
+			if union > 0 {
+				overlap = math.Round(float64(len(shared))/float64(union)*1000) / 1000
+			}
+			deltas.Pairs = append(deltas.Pairs, github.UserPairDelta{
+				Users:           [2]string{users[i].Login, users[j].Login},
+				SharedLanguages: shared,
+				LanguageOverlap: overlap,
+				HourCorrelation: hourCorrelation(users[i].FunStats.CommitsByHour, users[j].FunStats.CommitsByHour),
+			})
+		}
+	}
+	return deltas
+}
+
+// leader returns the login with the highest metric, or "" when the highest
+// value is shared.
+func leader(users []github.ComparedUser, metric func(github.ComparedUser) int) string {
+	best, login, tied := math.MinInt, "", false
+	for _, u := range users {
+		switch v := metric(u); {
+		case v > best:
+			best, login, tied = v, u.Login, false
+		case v == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return login
+}
+
+// hourCorrelation is the Pearson correlation of two commits-by-hour
+// histograms, rounded to three decimals. It is 0 when either user commits
+// uniformly or not at all.
+func hourCorrelation(a, b map[int]int) float64 {
+	var sumA, sumB float64
+	for h := 0; h < 24; h++ {
+		sumA += float64(a[h])
+		sumB += float64(b[h])
+	}
+	meanA, meanB := sumA/24, sumB/24
+
+	var cov, varA, varB float64
+	for h := 0; h < 24; h++ {
+		da, db := float64(a[h])-meanA, float64(b[h])-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return math.Round(cov/math.Sqrt(varA*varB)*1000) / 1000
+}
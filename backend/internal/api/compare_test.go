@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestParseCompareUsers(t *testing.T) {
+	users, err := parseCompareUsers(" alice,bob,Alice,,carol ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 3 || users[0] != "alice" || users[2] != "carol" {
+		t.Errorf("expected alice, bob, carol, got %v", users)
+	}
+	for _, param := range []string{"", "alice", "alice,ALICE", "a,b,c,d,e,f"} {
+		if _, err := parseCompareUsers(param); err == nil {
+			t.Errorf("expected an error for %q", param)
+		}
+	}
+}
+
+func TestHourCorrelation(t *testing.T) {
+	morning := map[int]int{8: 5, 9: 10, 10: 5}
+	if got := hourCorrelation(morning, map[int]int{8: 1, 9: 2, 10: 1}); got != 1 {
+		t.Errorf("expected identical patterns to correlate at 1, got %v", got)
+	}
+	if got := hourCorrelation(morning, map[int]int{22: 4, 23: 8}); got >= 0 {
+		t.Errorf("expected disjoint patterns to correlate negatively, got %v", got)
+	}
+	if got := hourCorrelation(morning, nil); got != 0 {
+		t.Errorf("expected 0 without commits, got %v", got)
+	}
+}
+
+func TestLeader_EmptyOnTie(t *testing.T) {
+	users := []github.ComparedUser{
+		{Login: "alice", Streak: github.StreakStats{LongestStreak: 5, CurrentStreak: 2}},
+		{Login: "bob", Streak: github.StreakStats{LongestStreak: 9, CurrentStreak: 2}},
+	}
+	if got := leader(users, func(u github.ComparedUser) int { return u.Streak.LongestStreak }); got != "bob" {
+		t.Errorf("expected bob to lead, got %q", got)
+	}
+	if got := leader(users, func(u github.ComparedUser) int { return u.Streak.CurrentStreak }); got != "" {
+		t.Errorf("expected no leader on a tie, got %q", got)
+	}
+}
+
+func TestAlignCalendars_FillsMissingDates(t *testing.T) {
+	calendars := [][]github.ContributionWeek{
+		{{Days: []github.ContributionDay{{Date: "2025-01-01", Count: 3}, {Date: "2025-01-02", Count: 1}}}},
+		{{Days: []github.ContributionDay{{Date: "2025-01-02", Count: 4}, {Date: "2025-01-03", Count: 2}}}},
+	}
+
+	aligned := alignCalendars(calendars)
+
+	if len(aligned.Dates) != 3 || aligned.Dates[0] != "2025-01-01" || aligned.Dates[2] != "2025-01-03" {
+		t.Fatalf("unexpected dates: %v", aligned.Dates)
+	}
+	if got := aligned.Counts[0]; got[0] != 3 || got[1] != 1 || got[2] != 0 {
+		t.Errorf("unexpected first calendar: %v", got)
+	}
+	if got := aligned.Counts[1]; got[0] != 0 || got[1] != 4 || got[2] != 2 {
+		t.Errorf("unexpected second calendar: %v", got)
+	}
+}
+
+func TestHandler_GetComparison_UsesCachedProfiles(t *testing.T) {
+	handler := newTestHandler()
+	morning := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	handler.store.SetStats("alice:public", &github.Stats{
+		Profile:   github.Profile{Login: "alice"},
+		Languages: []github.LanguageStats{{Name: "Go"}, {Name: "Rust"}},
+		Streak:    github.StreakStats{LongestStreak: 12},
+	})
+	handler.store.SetCommits("alice:public", []github.Commit{{Date: morning}, {Date: morning.Add(time.Hour)}})
+	handler.store.SetStats("bob:public", &github.Stats{
+		Profile:   github.Profile{Login: "bob"},
+		Languages: []github.LanguageStats{{Name: "Go"}, {Name: "Python"}},
+		Streak:    github.StreakStats{LongestStreak: 4},
+	})
+	handler.store.SetCommits("bob:public", []github.Commit{{Date: morning}})
+
+	w := httptest.NewRecorder()
+	handler.GetComparison(w, httptest.NewRequest(http.MethodGet, "/api/compare?users=alice,bob", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var comparison github.Comparison
+	if err := json.NewDecoder(w.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(comparison.Users) != 2 || comparison.Users[0].FunStats.TotalCommits != 2 {
+		t.Errorf("unexpected users: %+v", comparison.Users)
+	}
+	if comparison.Deltas.LongestStreak != "alice" {
+		t.Errorf("expected alice to have the longest streak, got %q", comparison.Deltas.LongestStreak)
+	}
+	if len(comparison.Deltas.SharedLanguages) != 1 || comparison.Deltas.SharedLanguages[0] != "Go" {
+		t.Errorf("expected Go shared, got %v", comparison.Deltas.SharedLanguages)
+	}
+	pair := comparison.Deltas.Pairs[0]
+	if pair.LanguageOverlap != 0.333 || pair.HourCorrelation <= 0 {
+		t.Errorf("unexpected pair delta: %+v", pair)
+	}
+}
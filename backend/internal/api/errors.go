@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+// writeError maps an error from the github package to a status code and a
+// JSON body of the form {"error": code, "message": text}. notFound and
+// fallback are the messages used for missing resources and unknown failures.
+func writeError(w http.ResponseWriter, err error, notFound, fallback string) {
+	status := http.StatusInternalServerError
+	body := map[string]any{
+		"error":   "internal_error",
+		"message": fallback,
+	}
+
+	var rateErr *github.RateLimitError
+	var graphqlErr *github.GraphQLError
+	var apiErr *github.APIError
+
+	switch {
+	case errors.As(err, &rateErr):
+		status = http.StatusTooManyRequests
+		body["error"] = "rate_limited"
+		body["message"] = "GitHub API rate limit exceeded. Please login for higher limits."
+		body["login_required"] = true
+		if !rateErr.Reset.IsZero() {
+			body["reset"] = rateErr.Reset.UTC().Format(time.RFC3339)
+			if wait := time.Until(rateErr.Reset); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			}
+		}
+	case errors.Is(err, github.ErrRateLimited):
+		status = http.StatusTooManyRequests
+		body["error"] = "rate_limited"
+		body["message"] = "GitHub API rate limit exceeded. Please login for higher limits."
+		body["login_required"] = true
+	case errors.Is(err, github.ErrNotFound):
+		status = http.StatusNotFound
+		body["error"] = "not_found"
+		body["message"] = notFound
+	case errors.Is(err, github.ErrUnauthorized):
+		status = http.StatusUnauthorized
+		body["error"] = "unauthorized"
+		body["message"] = "GitHub rejected the credentials. Please login again."
+		body["login_required"] = true
+	case errors.Is(err, github.ErrStatsPending):
+		status = http.StatusAccepted
+		body["error"] = "pending"
+		body["message"] = "GitHub is still computing these statistics. Please retry shortly."
+		w.Header().Set("Retry-After", "5")
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		body["error"] = "timeout"
+		body["message"] = "GitHub took too long to respond. Please retry."
+	case errors.As(err, &graphqlErr):
+		body["error"] = "graphql_error"
+		body["type"] = graphqlErr.Type
+		body["path"] = graphqlErr.Path
+	case errors.As(err, &apiErr):
+		body["error"] = "upstream_error"
+		body["status"] = apiErr.StatusCode
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestWriteError_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		code     string
+		message  string
+		loginReq bool
+	}{
+		{"not found", fmt.Errorf("failed to get profile: %w", github.ErrNotFound), http.StatusNotFound, "not_found", "user not found", false},
+		{"rate limited", &github.RateLimitError{Resource: "core", Reset: time.Now().Add(time.Minute)}, http.StatusTooManyRequests, "rate_limited", "", true},
+		{"unauthorized", github.ErrUnauthorized, http.StatusUnauthorized, "unauthorized", "", true},
+		{"pending", github.ErrStatsPending, http.StatusAccepted, "pending", "", false},
+		{"timeout", fmt.Errorf("failed to get profile: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "timeout", "", false},
+		{"graphql", &github.GraphQLError{Type: "SOMETHING", Message: "not found"}, http.StatusInternalServerError, "graphql_error", "failed", false},
+		{"api error 403", &github.APIError{StatusCode: 403, Message: "403"}, http.StatusInternalServerError, "upstream_error", "failed", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, tt.err, "user not found", "failed")
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+
+			var body map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body["error"] != tt.code {
+				t.Errorf("expected error %q, got %v", tt.code, body["error"])
+			}
+			if tt.message != "" && body["message"] != tt.message {
+				t.Errorf("expected message %q, got %v", tt.message, body["message"])
+			}
+			if tt.loginReq && body["login_required"] != true {
+				t.Error("expected login_required to be true")
+			}
+		})
+	}
+}
+
+func TestWriteError_RateLimitedSetsRetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, &github.RateLimitError{Resource: "core", Reset: time.Now().Add(30 * time.Second)}, "", "")
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls that share a key, so a burst of
+// identical requests results in a single upstream fetch.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// Do runs fn once for all concurrent callers with the same key and returns
+// its result to each of them. fn gets a context that is cancelled only once
+// every waiting caller has given up, so one closed browser tab does not
+// abort a fetch other requests are still waiting for.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		fnCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			call.val, call.err = fn(fnCtx)
+			cancel()
+
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// InFlight reports whether a call for key is currently running.
+func (g *flightGroup) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.calls[key]
+	return ok
+}
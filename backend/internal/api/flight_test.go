@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroup_DeduplicatesConcurrentCalls(t *testing.T) {
+	group := newFlightGroup()
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]any, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := group.Do(context.Background(), "key", func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				<-release
+				return "value", nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+
+	for !group.InFlight("key") {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("result %d: expected value, got %v", i, v)
+		}
+	}
+	if group.InFlight("key") {
+		t.Error("expected call to be finished")
+	}
+}
+
+func TestFlightGroup_CancelsWhenAllWaitersLeave(t *testing.T) {
+	group := newFlightGroup()
+	cancelled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := group.Do(ctx, "key", func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected shared call to be cancelled")
+	}
+}
+
+func TestFlightGroup_KeepsRunningWhileOtherWaitersRemain(t *testing.T) {
+	group := newFlightGroup()
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (any, error) {
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaving, leave := context.WithCancel(context.Background())
+	go group.Do(leaving, "key", fn)
+	for !group.InFlight("key") {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan any)
+	go func() {
+		v, _ := group.Do(context.Background(), "key", fn)
+		done <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	leave()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if v := <-done; v != "value" {
+		t.Errorf("expected value, got %v", v)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+// computeFunStats derives time-of-day and activity statistics from commits.
+// Hours, weekdays and days are taken in the location of each commit's date,
+// so callers localize commits first.
+func computeFunStats(commits []github.Commit, totalRepos int) github.FunStats {
+	commitsByHour := make(map[int]int)
+	commitsByDayOfWeek := make(map[string]int)
+	commitsByMonth := make(map[string]int)
+	commitsByRepo := make(map[string]int)
+
+	var weekendCommits, nightCommits, earlyCommits int
+	uniqueDays := make(map[string]bool)
+
+	for _, c := range commits {
+		commitsByHour[c.Date.Hour()]++
+		commitsByDayOfWeek[c.Date.Weekday().String()]++
+		commitsByMonth[c.Date.Format("2006-01")]++
+		commitsByRepo[c.Repo]++
+		uniqueDays[c.Date.Format("2006-01-02")] = true
+
+		hour := c.Date.Hour()
+		if hour >= 22 || hour < 6 {
+			nightCommits++
+		}
+		if hour >= 5 && hour < 9 {
+			earlyCommits++
+		}
+
+		if c.Date.Weekday() == time.Saturday || c.Date.Weekday() == time.Sunday {
+			weekendCommits++
+		}
+	}
+
+	mostProductiveHour := 0
+	maxHourCommits := 0
+	for hour, count := range commitsByHour {
+		if count > maxHourCommits {
+			maxHourCommits = count
+			mostProductiveHour = hour
+		}
+	}
+
+	mostProductiveDay := ""
+	maxDayCommits := 0
+	for day, count := range commitsByDayOfWeek {
+		if count > maxDayCommits {
+			maxDayCommits = count
+			mostProductiveDay = day
+		}
+	}
+
+	mostActiveRepo := ""
+	mostActiveRepoCommits := 0
+	for repo, count := range commitsByRepo {
+		if count > mostActiveRepoCommits {
+			mostActiveRepoCommits = count
+			mostActiveRepo = repo
+		}
+	}
+
+	totalDays := len(uniqueDays)
+	avgCommitsPerDay := 0.0
+	if totalDays > 0 {
+		avgCommitsPerDay = float64(len(commits)) / float64(totalDays)
+	}
+
+	avgCommitsByHour := make(map[int]float64)
+	for hour, count := range commitsByHour {
+		if totalDays > 0 {
+			avgCommitsByHour[hour] = float64(count) / float64(totalDays)
+		}
+	}
+
+	numWeeks := float64(totalDays) / 7.0
+	if numWeeks < 1 {
+		numWeeks = 1
+	}
+	avgCommitsByDayOfWeek := make(map[string]float64)
+	for day, count := range commitsByDayOfWeek {
+		avgCommitsByDayOfWeek[day] = float64(count) / numWeeks
+	}
+
+	numMonths := float64(len(commitsByMonth))
+	if numMonths < 1 {
+		numMonths = 1
+	}
+	avgCommitsByMonth := make(map[string]float64)
+	for month, count := range commitsByMonth {
+		avgCommitsByMonth[month] = float64(count) / numMonths
+	}
+
+	total := float64(len(commits))
+	weekendPercent := 0.0
+	nightPercent := 0.0
+	earlyPercent := 0.0
+	if total > 0 {
+		weekendPercent = float64(weekendCommits) / total * 100
+		nightPercent = float64(nightCommits) / total * 100
+		earlyPercent = float64(earlyCommits) / total * 100
+	}
+
+	longestStreak := calculateLongestStreak(commits)
+
+	return github.FunStats{
+		MostProductiveHour:    mostProductiveHour,
+		MostProductiveDay:     mostProductiveDay,
+		CommitsByHour:         commitsByHour,
+		CommitsByDayOfWeek:    commitsByDayOfWeek,
+		CommitsByMonth:        commitsByMonth,
+		AvgCommitsByHour:      avgCommitsByHour,
+		AvgCommitsByDayOfWeek: avgCommitsByDayOfWeek,
+		AvgCommitsByMonth:     avgCommitsByMonth,
+		AverageCommitsPerDay:  avgCommitsPerDay,
+		LongestCodingStreak:   longestStreak,
+		TotalCommits:          len(commits),
+		TotalRepositories:     totalRepos,
+		MostActiveRepo:        mostActiveRepo,
+		MostActiveRepoCommits: mostActiveRepoCommits,
+		WeekendWarriorPercent: weekendPercent,
+		NightOwlPercent:       nightPercent,
+		EarlyBirdPercent:      earlyPercent,
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"sort"
@@ -11,26 +13,39 @@ import (
 
 	"gh-stats/backend/internal/cache"
 	"gh-stats/backend/internal/github"
+	"gh-stats/backend/internal/history"
 
 	"github.com/go-chi/chi/v5"
 )
 
+const (
+	// requestTimeout bounds the upstream work done for a single API request.
+	requestTimeout = 30 * time.Second
+	// commitFetchTimeout bounds commit crawls, which page through many repositories.
+	commitFetchTimeout = 3 * time.Minute
+)
+
 type Handler struct {
-	store            *cache.Store
+	store            cache.Backend
 	oauth            *github.OAuthConfig
 	frontendURL      string
 	ranking          *github.RankingService
+	flights          *flightGroup
+	orgs             *memo
+	images           *memo
+	history          history.Store
+	tracker          *history.Tracker
 	publicClient     *github.Client
 	publicTokenOwner string // username of the GITHUB_TOKEN owner (to prevent exposing their private data)
 }
 
-func NewHandler(store *cache.Store, oauth *github.OAuthConfig, frontendURL string, githubToken string) *Handler {
+func NewHandler(store cache.Backend, oauth *github.OAuthConfig, frontendURL string, githubToken string) *Handler {
 	var publicClient *github.Client
 	var publicTokenOwner string
 
 	if githubToken != "" {
 		publicClient = github.NewClient(githubToken)
-		if profile, err := publicClient.GetProfile(""); err == nil {
+		if profile, err := publicClient.GetProfile(context.Background(), ""); err == nil {
 			publicTokenOwner = profile.Login
 			log.Printf("GITHUB_TOKEN owner: %s (their private data protected from public access)", publicTokenOwner)
 		}
@@ -43,26 +58,30 @@ func NewHandler(store *cache.Store, oauth *github.OAuthConfig, frontendURL strin
 		oauth:            oauth,
 		frontendURL:      frontendURL,
 		ranking:          github.NewRankingServiceWithToken(githubToken),
+		flights:          newFlightGroup(),
+		orgs:             newMemo(cache.StatsCacheTTL, maxCachedOrgs),
+		images:           newMemo(cache.StatsCacheTTL+cache.StaleGracePeriod, maxCachedImages),
+		history:          history.NewMemoryStore(),
+		tracker:          history.NewTracker(history.DefaultIdle),
 		publicClient:     publicClient,
 		publicTokenOwner: publicTokenOwner,
 	}
 }
 
-func (h *Handler) writeRateLimitError(w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusTooManyRequests)
-	json.NewEncoder(w).Encode(map[string]any{
-		"error":          "rate_limited",
-		"message":        "GitHub API rate limit exceeded. Please login for higher limits.",
-		"login_required": true,
-	})
-}
-
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
 }
 
+func (h *Handler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	client := h.getClientForRequest(r)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"limits": client.RateLimits(),
+	})
+}
+
 func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -71,14 +90,12 @@ func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := h.getClientForRequest(r)
-	users, err := client.SearchUsers(query)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	users, err := client.SearchUsers(ctx, query)
 	if err != nil {
 		log.Printf("search users error: %v", err)
-		if strings.Contains(err.Error(), "403") {
-			h.writeRateLimitError(w)
-			return
-		}
-		http.Error(w, "search failed", http.StatusInternalServerError)
+		writeError(w, err, "no users found", "search failed")
 		return
 	}
 
@@ -104,8 +121,25 @@ func (h *Handler) GetUserStats(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "visibility must be public, private, or all", http.StatusBadRequest)
 		return
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	languageMode := r.URL.Query().Get("languageMode")
+	if languageMode != "" && languageMode != "repos" && languageMode != "bytes" {
+		http.Error(w, "languageMode must be repos or bytes", http.StatusBadRequest)
+		return
+	}
 
 	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
@@ -114,46 +148,33 @@ func (h *Handler) GetUserStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
-	}
+	h.tracker.Viewed(username)
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
 
-	stats := h.store.GetStats(cacheKey)
-	if stats == nil {
-		var err error
-		stats, err = client.GetStatsWithVisibility(username, visibility)
-		if err != nil {
-			log.Printf("get stats error for %s: %v", username, err)
-			if strings.Contains(err.Error(), "not found") {
-				http.Error(w, "user not found", http.StatusNotFound)
-				return
-			}
-			if strings.Contains(err.Error(), "403") {
-				h.writeRateLimitError(w)
-				return
-			}
-			http.Error(w, "failed to fetch stats", http.StatusInternalServerError)
-			return
-		}
-		h.store.SetStats(cacheKey, stats)
-
-		go func() {
-			commits, err := client.GetAllCommitsWithLimit(username, stats.Repositories, 20)
-			if err != nil {
-				log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
-				return
-			}
-			h.store.SetCommits(cacheKey, commits)
-			log.Printf("Fetched %d commits for %s", len(commits), username)
-		}()
+	stats, info, err := h.loadStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		log.Printf("get stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch stats")
+		return
 	}
 
 	lang := r.URL.Query().Get("language")
 	if lang != "" {
 		stats = filterStatsByLanguage(stats, lang)
 	}
+	if loc != nil {
+		localized := *stats
+		localized.Streak = streakIn(stats, loc)
+		stats = &localized
+	}
+	// Byte sizes need GraphQL; without them repo counts are served instead.
+	if languageMode == "bytes" && stats.LanguagesByBytes != nil {
+		weighted := *stats
+		weighted.Languages = stats.LanguagesByBytes
+		stats = &weighted
+	}
 
+	writeCacheHeaders(w, info)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
@@ -183,16 +204,18 @@ func (h *Handler) GetUserRepositories(w http.ResponseWriter, r *http.Request) {
 	if visibility == "" {
 		visibility = "public"
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
-	}
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
 
-	stats := h.store.GetStats(cacheKey)
+	stats, info := h.cachedStats(h.getClientForUser(r, username), username, opts, cacheKey)
 	if stats == nil {
 		http.Error(w, "stats not available, fetch user stats first", http.StatusServiceUnavailable)
 		return
@@ -213,6 +236,7 @@ func (h *Handler) GetUserRepositories(w http.ResponseWriter, r *http.Request) {
 		repos = filtered
 	}
 
+	writeCacheHeaders(w, info)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
 		"count":        len(repos),
@@ -233,16 +257,24 @@ func (h *Handler) GetUserRepoStats(w http.ResponseWriter, r *http.Request) {
 	if visibility == "" {
 		visibility = "public"
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
+	coAuthored, err := parseCoAuthored(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	stats := h.store.GetStats(cacheKey)
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
+
+	stats, info := h.cachedStats(h.getClientForUser(r, username), username, opts, cacheKey)
 	if stats == nil {
 		http.Error(w, "stats not available", http.StatusServiceUnavailable)
 		return
@@ -261,10 +293,10 @@ func (h *Handler) GetUserRepoStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commits := h.store.GetCommits(cacheKey)
+	commits := h.store.GetCommits(commitsCacheKey(cacheKey, coAuthored))
 	var repoCommits []github.Commit
 	for _, c := range commits {
-		if strings.EqualFold(c.Repo, repoName) {
+		if strings.EqualFold(c.Repo, repo.Key()) {
 			repoCommits = append(repoCommits, c)
 		}
 	}
@@ -296,6 +328,7 @@ func (h *Handler) GetUserRepoStats(w http.ResponseWriter, r *http.Request) {
 		CommitsByHour: commitsByHour,
 	}
 
+	writeCacheHeaders(w, info)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(repoStats)
 }
@@ -311,6 +344,22 @@ func (h *Handler) GetUserFunStats(w http.ResponseWriter, r *http.Request) {
 	if visibility == "" {
 		visibility = "public"
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coAuthored, err := parseCoAuthored(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	yearStr := r.URL.Query().Get("year")
 	monthStr := r.URL.Query().Get("month")
@@ -343,6 +392,8 @@ func (h *Handler) GetUserFunStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), commitFetchTimeout)
+	defer cancel()
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
@@ -351,174 +402,81 @@ func (h *Handler) GetUserFunStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
-	}
-
-	stats := h.store.GetStats(cacheKey)
-	commits := h.store.GetCommits(cacheKey)
-
-	if stats == nil && isOwnProfile {
-		fallbackKey := username + ":public"
-		stats = h.store.GetStats(fallbackKey)
-		commits = h.store.GetCommits(fallbackKey)
-	}
-
-	if stats == nil {
-		var err error
-		stats, err = client.GetStatsWithVisibility(username, visibility)
-		if err != nil {
-			log.Printf("get stats error for %s: %v", username, err)
-			if strings.Contains(err.Error(), "not found") {
-				http.Error(w, "user not found", http.StatusNotFound)
-				return
-			}
-			if strings.Contains(err.Error(), "403") {
-				h.writeRateLimitError(w)
-				return
-			}
-			http.Error(w, "failed to fetch stats", http.StatusInternalServerError)
-			return
-		}
-		h.store.SetStats(cacheKey, stats)
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
 
-		commits, err = client.GetAllCommitsWithLimit(username, stats.Repositories, 20)
-		if err != nil {
-			log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
-			commits = []github.Commit{}
-		} else {
-			h.store.SetCommits(cacheKey, commits)
-			log.Printf("Fetched %d commits for %s (fun stats)", len(commits), username)
+	if own, _ := h.store.GetStatsWithAge(cacheKey); isOwnProfile && own == nil {
+		public := opts
+		public.Visibility = "public"
+		publicKey := statsCacheKey(username, false, public)
+		if cached, _ := h.store.GetStatsWithAge(publicKey); cached != nil {
+			cacheKey = publicKey
 		}
 	}
 
-	if commits == nil {
-		commits = []github.Commit{}
-	}
-
-	filteredCommits := filterCommitsByDate(commits, filterYear, filterMonth, filterDay)
-
-	commitsByHour := make(map[int]int)
-	commitsByDayOfWeek := make(map[string]int)
-	commitsByMonth := make(map[string]int)
-	commitsByRepo := make(map[string]int)
-
-	var weekendCommits, nightCommits, earlyCommits int
-	uniqueDays := make(map[string]bool)
-
-	for _, c := range filteredCommits {
-		commitsByHour[c.Date.Hour()]++
-		commitsByDayOfWeek[c.Date.Weekday().String()]++
-		commitsByMonth[c.Date.Format("2006-01")]++
-		commitsByRepo[c.Repo]++
-		uniqueDays[c.Date.Format("2006-01-02")] = true
-
-		hour := c.Date.Hour()
-		if hour >= 22 || hour < 6 {
-			nightCommits++
-		}
-		if hour >= 5 && hour < 9 {
-			earlyCommits++
-		}
-
-		if c.Date.Weekday() == time.Saturday || c.Date.Weekday() == time.Sunday {
-			weekendCommits++
-		}
+	stats, info, err := h.loadStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		log.Printf("get stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch stats")
+		return
 	}
 
-	mostProductiveHour := 0
-	maxHourCommits := 0
-	for hour, count := range commitsByHour {
-		if count > maxHourCommits {
-			maxHourCommits = count
-			mostProductiveHour = hour
-		}
+	commits, err := h.loadCommits(ctx, client, username, cacheKey, stats, coAuthored)
+	if err != nil {
+		log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
 	}
 
-	mostProductiveDay := ""
-	maxDayCommits := 0
-	for day, count := range commitsByDayOfWeek {
-		if count > maxDayCommits {
-			maxDayCommits = count
-			mostProductiveDay = day
-		}
+	if commits == nil {
+		commits = []github.Commit{}
 	}
 
-	mostActiveRepo := ""
-	mostActiveRepoCommits := 0
-	for repo, count := range commitsByRepo {
-		if count > mostActiveRepoCommits {
-			mostActiveRepoCommits = count
-			mostActiveRepo = repo
-		}
+	if loc == nil {
+		loc = inferTimezone(commits)
 	}
+	filteredCommits := filterCommitsByDate(localizeCommits(commits, loc), filterYear, filterMonth, filterDay)
 
-	totalDays := len(uniqueDays)
-	avgCommitsPerDay := 0.0
-	if totalDays > 0 {
-		avgCommitsPerDay = float64(len(filteredCommits)) / float64(totalDays)
-	}
+	funStats := computeFunStats(filteredCommits, len(stats.Repositories))
+	funStats.Timezone = loc.String()
 
-	avgCommitsByHour := make(map[int]float64)
-	for hour, count := range commitsByHour {
-		if totalDays > 0 {
-			avgCommitsByHour[hour] = float64(count) / float64(totalDays)
-		}
-	}
+	writeCacheHeaders(w, info)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(funStats)
+}
 
-	numWeeks := float64(totalDays) / 7.0
-	if numWeeks < 1 {
-		numWeeks = 1
-	}
-	avgCommitsByDayOfWeek := make(map[string]float64)
-	for day, count := range commitsByDayOfWeek {
-		avgCommitsByDayOfWeek[day] = float64(count) / numWeeks
+// parseRepoOptions reads the affiliation, includeForks and includeArchived
+// parameters selecting the repositories stats cover.
+func parseRepoOptions(r *http.Request, visibility string) (github.RepoOptions, error) {
+	opts := github.RepoOptions{Visibility: visibility}
+	affiliations, err := github.ParseAffiliations(r.URL.Query().Get("affiliation"))
+	if err != nil {
+		return opts, errors.New("affiliation must list owner, collaborator or organization_member")
 	}
-
-	numMonths := float64(len(commitsByMonth))
-	if numMonths < 1 {
-		numMonths = 1
+	opts.Affiliations = affiliations
+	if opts.IncludeForks, err = parseBoolParam(r, "includeForks"); err != nil {
+		return opts, err
 	}
-	avgCommitsByMonth := make(map[string]float64)
-	for month, count := range commitsByMonth {
-		avgCommitsByMonth[month] = float64(count) / numMonths
+	if opts.IncludeArchived, err = parseBoolParam(r, "includeArchived"); err != nil {
+		return opts, err
 	}
+	return opts, nil
+}
 
-	total := float64(len(filteredCommits))
-	weekendPercent := 0.0
-	nightPercent := 0.0
-	earlyPercent := 0.0
-	if total > 0 {
-		weekendPercent = float64(weekendCommits) / total * 100
-		nightPercent = float64(nightCommits) / total * 100
-		earlyPercent = float64(earlyCommits) / total * 100
+// parseBoolParam reads an optional boolean query parameter.
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
 	}
-
-	longestStreak := calculateLongestStreak(filteredCommits)
-
-	funStats := github.FunStats{
-		MostProductiveHour:    mostProductiveHour,
-		MostProductiveDay:     mostProductiveDay,
-		CommitsByHour:         commitsByHour,
-		CommitsByDayOfWeek:    commitsByDayOfWeek,
-		CommitsByMonth:        commitsByMonth,
-		AvgCommitsByHour:      avgCommitsByHour,
-		AvgCommitsByDayOfWeek: avgCommitsByDayOfWeek,
-		AvgCommitsByMonth:     avgCommitsByMonth,
-		AverageCommitsPerDay:  avgCommitsPerDay,
-		LongestCodingStreak:   longestStreak,
-		TotalCommits:          len(filteredCommits),
-		TotalRepositories:     len(stats.Repositories),
-		MostActiveRepo:        mostActiveRepo,
-		MostActiveRepoCommits: mostActiveRepoCommits,
-		WeekendWarriorPercent: weekendPercent,
-		NightOwlPercent:       nightPercent,
-		EarlyBirdPercent:      earlyPercent,
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New(name + " must be true or false")
 	}
+	return b, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(funStats)
+// parseCoAuthored reads the optional coauthored query parameter, which adds
+// commits crediting the user in a Co-authored-by trailer.
+func parseCoAuthored(r *http.Request) (bool, error) {
+	return parseBoolParam(r, "coauthored")
 }
 
 func filterCommitsByDate(commits []github.Commit, year, month, day int) []github.Commit {
@@ -560,19 +518,22 @@ func (h *Handler) GetUserContributions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
 	client := h.getClientForUser(r, username)
-	contributions, total, err := client.GetContributionsForYear(username, year)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	contributions, total, err := client.GetContributionsForYear(ctx, username, year)
 	if err != nil {
 		log.Printf("get contributions error for %s: %v", username, err)
-		if strings.Contains(err.Error(), "not found") {
-			http.Error(w, "user not found", http.StatusNotFound)
-			return
-		}
-		if strings.Contains(err.Error(), "403") {
-			h.writeRateLimitError(w)
-			return
-		}
-		http.Error(w, "failed to fetch contributions", http.StatusInternalServerError)
+		writeError(w, err, "user not found", "failed to fetch contributions")
 		return
 	}
 
@@ -581,9 +542,41 @@ func (h *Handler) GetUserContributions(w http.ResponseWriter, r *http.Request) {
 		"contributions":      contributions,
 		"totalContributions": total,
 		"year":               year,
+		"streak":             github.StreakAt(contributions, total, time.Now().In(loc)),
+		"timezone":           loc.String(),
 	})
 }
 
+func (h *Handler) GetUserLifetimeContributions(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	lifetime, err := client.GetLifetimeContributions(ctx, username)
+	if err != nil {
+		log.Printf("get lifetime contributions error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch contributions")
+		return
+	}
+	if loc != nil {
+		lifetime.Streak = github.StreakAt(lifetime.Contributions, lifetime.Streak.TotalContributions, time.Now().In(loc))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lifetime)
+}
+
 func (h *Handler) GetUserRepoCommits(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
 	if username == "" {
@@ -595,20 +588,30 @@ func (h *Handler) GetUserRepoCommits(w http.ResponseWriter, r *http.Request) {
 	if visibility == "" {
 		visibility = "public"
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
+	coAuthored, err := parseCoAuthored(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	commits := h.store.GetCommits(cacheKey)
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
+
+	commits := h.store.GetCommits(commitsCacheKey(cacheKey, coAuthored))
 	if commits == nil {
 		// Try public cache if authenticated cache is empty
 		if isOwnProfile {
-			commits = h.store.GetCommits(username + ":public")
+			public := opts
+			public.Visibility = "public"
+			commits = h.store.GetCommits(commitsCacheKey(statsCacheKey(username, false, public), coAuthored))
 		}
 	}
 
@@ -626,6 +629,27 @@ func (h *Handler) GetUserRepoCommits(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) GetUserPullStats(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+
+	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	pulls, err := client.GetPullStats(ctx, username)
+	if err != nil {
+		log.Printf("get pull stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch pull request stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pulls)
+}
+
 func (h *Handler) GetUserFollowers(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
 	if username == "" {
@@ -634,14 +658,12 @@ func (h *Handler) GetUserFollowers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := h.getClientForUser(r, username)
-	followers, err := client.GetFollowers(username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	followers, err := client.GetFollowers(ctx, username)
 	if err != nil {
 		log.Printf("get followers error: %v", err)
-		if strings.Contains(err.Error(), "403") {
-			h.writeRateLimitError(w)
-			return
-		}
-		http.Error(w, "failed to fetch followers", http.StatusInternalServerError)
+		writeError(w, err, "user not found", "failed to fetch followers")
 		return
 	}
 
@@ -660,14 +682,12 @@ func (h *Handler) GetUserFollowing(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := h.getClientForUser(r, username)
-	following, err := client.GetFollowing(username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	following, err := client.GetFollowing(ctx, username)
 	if err != nil {
 		log.Printf("get following error: %v", err)
-		if strings.Contains(err.Error(), "403") {
-			h.writeRateLimitError(w)
-			return
-		}
-		http.Error(w, "failed to fetch following", http.StatusInternalServerError)
+		writeError(w, err, "user not found", "failed to fetch following")
 		return
 	}
 
@@ -731,14 +751,16 @@ func (h *Handler) GetCountryRanking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ranking, err := h.ranking.GetCountryRanking(country)
+	metric, err := github.ParseRankingMetric(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ranking, err := h.ranking.GetCountryRankingBy(country, metric)
 	if err != nil {
 		log.Printf("get country ranking error: %v", err)
-		if strings.Contains(err.Error(), "not found") {
-			http.Error(w, "country not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "failed to fetch ranking", http.StatusInternalServerError)
+		writeError(w, err, "country not found", "failed to fetch ranking")
 		return
 	}
 
@@ -746,6 +768,61 @@ func (h *Handler) GetCountryRanking(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(ranking)
 }
 
+// GetCountryMovers lists the users who moved furthest up and down a
+// country ranking since yesterday or, with period=week, last week.
+func (h *Handler) GetCountryMovers(w http.ResponseWriter, r *http.Request) {
+	country := chi.URLParam(r, "country")
+	if country == "" {
+		http.Error(w, "country required", http.StatusBadRequest)
+		return
+	}
+	var days int
+	switch r.URL.Query().Get("period") {
+	case "", "day":
+		days = 1
+	case "week":
+		days = 7
+	default:
+		http.Error(w, "period must be day or week", http.StatusBadRequest)
+		return
+	}
+	limit, err := parseIntParam(r, "limit", 1, 100)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = 10
+	}
+
+	movers, err := h.ranking.GetCountryMovers(country, days, limit)
+	if err != nil {
+		log.Printf("get country movers error: %v", err)
+		writeError(w, err, "country not found", "failed to fetch ranking")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movers)
+}
+
+// SetRankingSource replaces where country rankings come from.
+func (h *Handler) SetRankingSource(source github.RankingSource) {
+	h.ranking.SetSource(source)
+}
+
+// StartRankingPreload keeps every country ranking loaded until ctx is
+// cancelled.
+func (h *Handler) StartRankingPreload(ctx context.Context) {
+	go h.ranking.RunPreloader(ctx)
+}
+
+// GetRankingStatus reports how far loading every country ranking got.
+func (h *Handler) GetRankingStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.ranking.LoadStatus())
+}
+
 func (h *Handler) GetGlobalRanking(w http.ResponseWriter, r *http.Request) {
 	limitStr := r.URL.Query().Get("limit")
 	limit := 100
@@ -755,7 +832,13 @@ func (h *Handler) GetGlobalRanking(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	users := h.ranking.GetGlobalRanking(limit)
+	metric, err := github.ParseRankingMetric(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users := h.ranking.GetGlobalRankingBy(metric, limit)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
@@ -771,9 +854,14 @@ func (h *Handler) GetUserRanking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metric, err := github.ParseRankingMetric(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	country := r.URL.Query().Get("country")
 	var ranking *github.UserRanking
-	var err error
 
 	if country != "" {
 		ranking, err = h.ranking.GetUserRanking(username, country)
@@ -783,11 +871,7 @@ func (h *Handler) GetUserRanking(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Printf("get user ranking error: %v", err)
-		if strings.Contains(err.Error(), "not found") {
-			http.Error(w, "country not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "failed to fetch ranking", http.StatusInternalServerError)
+		writeError(w, err, "country not found", "failed to fetch ranking")
 		return
 	}
 
@@ -798,6 +882,7 @@ func (h *Handler) GetUserRanking(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	ranking.SortBy(metric)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
@@ -817,8 +902,15 @@ func (h *Handler) GetUserCodeFrequency(w http.ResponseWriter, r *http.Request) {
 	if visibility == "" {
 		visibility = "public"
 	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
 	session := h.getSession(r)
 	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
 
@@ -827,35 +919,19 @@ func (h *Handler) GetUserCodeFrequency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cacheKey := username + ":" + visibility
-	if isOwnProfile {
-		cacheKey = username + ":auth:" + visibility
-	}
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
 
-	stats := h.store.GetStats(cacheKey)
-	if stats == nil {
-		var err error
-		stats, err = client.GetStatsWithVisibility(username, visibility)
-		if err != nil {
-			log.Printf("get stats error for %s: %v", username, err)
-			if strings.Contains(err.Error(), "not found") {
-				http.Error(w, "user not found", http.StatusNotFound)
-				return
-			}
-			if strings.Contains(err.Error(), "403") {
-				h.writeRateLimitError(w)
-				return
-			}
-			http.Error(w, "failed to fetch stats", http.StatusInternalServerError)
-			return
-		}
-		h.store.SetStats(cacheKey, stats)
+	stats, _, err := h.loadStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		log.Printf("get stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch stats")
+		return
 	}
 
-	codeFreq, err := client.GetCodeFrequency(username, stats.Repositories)
+	codeFreq, err := client.GetCodeFrequency(ctx, username, stats.Repositories)
 	if err != nil {
 		log.Printf("get code frequency error for %s: %v", username, err)
-		http.Error(w, "failed to fetch code frequency", http.StatusInternalServerError)
+		writeError(w, err, "user not found", "failed to fetch code frequency")
 		return
 	}
 
@@ -151,6 +151,58 @@ func TestHandler_GetUserRepositories_ReturnsRepositoriesFromCache(t *testing.T)
 	}
 }
 
+// agedStore reports every cached entry as age old.
+type agedStore struct {
+	cache.Backend
+	age time.Duration
+}
+
+func (s agedStore) GetStatsWithAge(key string) (*github.Stats, time.Duration) {
+	stats, _ := s.Backend.GetStatsWithAge(key)
+	return stats, s.age
+}
+
+func TestHandler_GetUserRepositories_ServesStaleStatsWhileRefreshing(t *testing.T) {
+	refreshed := make(chan struct{}, 1)
+	mockServer := setupMockGitHubServer(map[string]http.HandlerFunc{
+		"/": func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+			http.NotFound(w, r)
+		},
+	})
+	defer mockServer.Close()
+	defer github.SetAPIURL(github.SetAPIURL(mockServer.URL))
+	defer github.SetGraphQLURL(github.SetGraphQLURL(mockServer.URL + "/graphql"))
+
+	handler := newTestHandler()
+	handler.store.SetStats("testuser:public", &github.Stats{
+		Repositories: []github.Repository{{Name: "repo1", Language: "Go"}},
+	})
+	handler.store = agedStore{Backend: handler.store, age: cache.StatsCacheTTL + time.Minute}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/testuser/repositories", nil)
+	w := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/repositories", handler.GetUserRepositories)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("X-Cache-Status"); got != "stale" {
+		t.Errorf("expected X-Cache-Status stale, got %q", got)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Error("expected stale stats to start a background refresh")
+	}
+}
+
 func TestHandler_GetUserRepositories_FiltersRepositoriesByQuery(t *testing.T) {
 	handler := newTestHandler()
 	handler.store.SetStats("testuser:public", &github.Stats{
@@ -339,3 +391,74 @@ func TestFilterStatsByLanguage_NoMatch(t *testing.T) {
 		t.Errorf("expected 0 repos, got %d", len(filtered.Repositories))
 	}
 }
+
+func TestHandler_GetUserStats_InvalidLanguageMode(t *testing.T) {
+	handler := NewHandler(cache.New(), nil, "http://localhost:3000", "")
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/stats", handler.GetUserStats)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/testuser/stats?languageMode=lines", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_GetUserStats_InvalidRepoOptions(t *testing.T) {
+	handler := NewHandler(cache.New(), nil, "http://localhost:3000", "")
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/stats", handler.GetUserStats)
+	for _, query := range []string{"affiliation=member", "includeForks=maybe", "includeArchived=2"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/testuser/stats?"+query, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestHandler_GetUserRepositories_KeysCacheByRepoOptions(t *testing.T) {
+	handler := newTestHandler()
+	handler.store.SetStats("testuser:public", &github.Stats{
+		Repositories: []github.Repository{{Name: "repo1"}},
+	})
+	handler.store.SetStats("testuser:public:forks", &github.Stats{
+		Repositories: []github.Repository{{Name: "repo1"}, {Name: "fork", Fork: true}},
+	})
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/repositories", handler.GetUserRepositories)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/testuser/repositories?includeForks=true", nil))
+
+	var response map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response["count"].(float64) != 2 {
+		t.Errorf("expected the fork-inclusive entry with 2 repos, got %v", response["count"])
+	}
+}
+
+func TestHandler_RankingEndpoints_InvalidSort(t *testing.T) {
+	handler := newTestHandler()
+	r := chi.NewRouter()
+	r.Get("/api/rankings/global", handler.GetGlobalRanking)
+	r.Get("/api/rankings/country/{country}", handler.GetCountryRanking)
+	r.Get("/api/rankings/user/{username}", handler.GetUserRanking)
+
+	for _, path := range []string{
+		"/api/rankings/global?sort=stars",
+		"/api/rankings/country/lithuania?sort=stars",
+		"/api/rankings/user/testuser?sort=stars",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusBadRequest, w.Code)
+		}
+	}
+}
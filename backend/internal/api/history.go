@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"gh-stats/backend/internal/github"
+	"gh-stats/backend/internal/history"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// snapshotGap is the minimum time between snapshots recorded as a side
+// effect of refreshing stats. Later snapshots of a day replace earlier ones.
+const snapshotGap = time.Hour
+
+// SetHistory replaces the snapshot store, which is in memory by default.
+func (h *Handler) SetHistory(store history.Store) {
+	h.history = store
+}
+
+// SetTracker replaces the set of users snapshotted, which is empty by
+// default.
+func (h *Handler) SetTracker(tracker *history.Tracker) {
+	h.tracker = tracker
+}
+
+// SetRankHistory replaces where country ranking snapshots are kept, in
+// memory by default.
+func (h *Handler) SetRankHistory(store github.RankHistory) {
+	h.ranking.SetRankHistory(store)
+}
+
+// StartSnapshots snapshots every active tracked user once per interval
+// until ctx is cancelled.
+func (h *Handler) StartSnapshots(ctx context.Context, interval time.Duration) {
+	go history.NewRecorder(h.history, h.tracker, interval, h.snapshotUser).Run(ctx)
+}
+
+// GetUserHistory returns a user's recorded snapshots, optionally since a
+// date, and how the metrics changed over them. It only reads the store, so
+// looking up a user never starts tracking them.
+func (h *Handler) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		if since, err = time.Parse("2006-01-02", s); err != nil {
+			http.Error(w, "since must be a date such as 2025-01-31", http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.tracker.Viewed(username)
+	series, err := h.history.Series(username, since)
+	if err != nil {
+		log.Printf("get history error for %s: %v", username, err)
+		http.Error(w, "failed to read history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"username":  username,
+		"tracked":   h.tracker.Tracked(username),
+		"snapshots": series,
+		"change":    history.Delta(series),
+	})
+}
+
+// snapshotUser loads a user's public stats and snapshots them.
+func (h *Handler) snapshotUser(ctx context.Context, username string) (history.Snapshot, error) {
+	opts := github.RepoOptions{Visibility: "public"}
+	stats, _, err := h.loadStats(ctx, h.getPublicClient(username), username, opts, statsCacheKey(username, false, opts))
+	if err != nil {
+		return history.Snapshot{}, err
+	}
+	return h.snapshotOf(username, stats), nil
+}
+
+// recordSnapshot records refreshed public stats of a tracked user unless
+// they were snapshotted recently.
+func (h *Handler) recordSnapshot(username string, stats *github.Stats) {
+	if !h.tracker.Tracked(username) {
+		return
+	}
+	if latest, ok := h.history.Latest(username); ok && time.Since(latest.Time) < snapshotGap {
+		return
+	}
+	if err := h.history.Record(h.snapshotOf(username, stats)); err != nil {
+		log.Printf("Warning: failed to record snapshot for %s: %v", username, err)
+	}
+}
+
+// snapshotOf extracts the tracked metrics from stats. Ranks come from the
+// country rankings already loaded, so snapshots never fetch them.
+func (h *Handler) snapshotOf(username string, stats *github.Stats) history.Snapshot {
+	login := stats.Profile.Login
+	if login == "" {
+		login = username
+	}
+	snapshot := history.Snapshot{
+		Username:      login,
+		Time:          time.Now(),
+		Followers:     stats.Profile.Followers,
+		Contributions: stats.Streak.TotalContributions,
+		CurrentStreak: stats.Streak.CurrentStreak,
+		LongestStreak: stats.Streak.LongestStreak,
+	}
+	for _, repo := range stats.Repositories {
+		snapshot.Stars += repo.Stars
+	}
+	if ranking, _ := h.ranking.FindUserRanking(login); ranking != nil {
+		snapshot.Country = ranking.Country
+		snapshot.CountryRank = ranking.CountryRank
+		snapshot.GlobalRank = ranking.GlobalRank
+	}
+	return snapshot
+}
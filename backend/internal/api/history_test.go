@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+	"gh-stats/backend/internal/history"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler_GetUserHistory_ReadsWithoutTracking(t *testing.T) {
+	handler := newTestHandler()
+	handler.history.Record(history.Snapshot{Username: "testuser", Time: time.Now().AddDate(0, 0, -40), Followers: 2})
+	handler.history.Record(history.Snapshot{Username: "testuser", Time: time.Now().AddDate(0, 0, -10), Followers: 10, Stars: 5})
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/history", handler.GetUserHistory)
+	since := time.Now().AddDate(0, 0, -20).Format("2006-01-02")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/testuser/history?since="+since, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Tracked   bool               `json:"tracked"`
+		Snapshots []history.Snapshot `json:"snapshots"`
+		Change    history.Change     `json:"change"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Snapshots) != 1 || resp.Change != (history.Change{}) || resp.Tracked {
+		t.Errorf("expected only the snapshot since %s of an untracked user, got %+v", since, resp)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/newuser/history", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if users := handler.history.Users(); len(users) != 1 {
+		t.Errorf("expected viewing history not to record snapshots, got users %v", users)
+	}
+	if handler.tracker.Tracked("newuser") {
+		t.Error("expected viewing history not to track the user")
+	}
+}
+
+func TestHandler_RecordSnapshot_OnlyTrackedUsers(t *testing.T) {
+	handler := newTestHandler()
+	stats := &github.Stats{
+		Profile:      github.Profile{Login: "testuser", Followers: 12},
+		Repositories: []github.Repository{{Name: "a", Stars: 3}, {Name: "b", Stars: 4}},
+		Streak:       github.StreakStats{TotalContributions: 250, CurrentStreak: 2, LongestStreak: 9},
+	}
+	if err := handler.tracker.Track("testuser"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler.recordSnapshot("testuser", stats)
+	latest, ok := handler.history.Latest("testuser")
+	if !ok {
+		t.Fatal("expected a snapshot of a tracked user")
+	}
+	if latest.Followers != 12 || latest.Stars != 7 || latest.Contributions != 250 || latest.LongestStreak != 9 {
+		t.Errorf("unexpected snapshot %+v", latest)
+	}
+
+	handler.recordSnapshot("other", stats)
+	if _, ok := handler.history.Latest("other"); ok {
+		t.Error("expected no snapshot of an untracked user")
+	}
+}
+
+func TestHandler_GetUserHistory_InvalidSince(t *testing.T) {
+	handler := newTestHandler()
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/history", handler.GetUserHistory)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/testuser/history?since=yesterday", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
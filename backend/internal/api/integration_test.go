@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"gh-stats/backend/internal/cache"
 	"gh-stats/backend/internal/github"
@@ -291,3 +294,71 @@ func TestIntegration_CachedStats_ReturnedWithoutAPICall(t *testing.T) {
 		t.Errorf("expected cached user, got %s", response.Profile.Name)
 	}
 }
+
+func TestIntegration_ConcurrentStatsRequests_ShareOneFetch(t *testing.T) {
+	var profileCalls atomic.Int32
+	mockServer := setupMockGitHubServer(map[string]http.HandlerFunc{
+		"/users/popular": func(w http.ResponseWriter, r *http.Request) {
+			profileCalls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			json.NewEncoder(w).Encode(map[string]any{"login": "popular"})
+		},
+		"/users/popular/repos": func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]map[string]any{})
+		},
+	})
+	defer mockServer.Close()
+
+	originalAPIURL := github.SetAPIURL(mockServer.URL)
+	originalGraphQLURL := github.SetGraphQLURL(mockServer.URL + "/graphql")
+	defer func() {
+		github.SetAPIURL(originalAPIURL)
+		github.SetGraphQLURL(originalGraphQLURL)
+	}()
+
+	handler := NewHandler(cache.New(), nil, "http://localhost:3000", "")
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/stats", handler.GetUserStats)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/popular/stats", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if profileCalls.Load() != 1 {
+		t.Errorf("expected 1 profile fetch, got %d", profileCalls.Load())
+	}
+}
+
+func TestIntegration_GetUserStats_ReportsCacheStatus(t *testing.T) {
+	store := cache.New()
+	store.SetStats("cached:public", &github.Stats{Profile: github.Profile{Login: "cached"}})
+	handler := NewHandler(store, nil, "http://localhost:3000", "")
+
+	r := chi.NewRouter()
+	r.Get("/api/users/{username}/stats", handler.GetUserStats)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/cached/stats", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("X-Cache-Status"); got != "fresh" {
+		t.Errorf("expected X-Cache-Status fresh, got %q", got)
+	}
+	if got := w.Header().Get("X-Cache-Refreshing"); got != "false" {
+		t.Errorf("expected X-Cache-Refreshing false, got %q", got)
+	}
+	if w.Header().Get("Age") == "" {
+		t.Error("expected Age header")
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gh-stats/backend/internal/cache"
+	"gh-stats/backend/internal/github"
+)
+
+// commitRepoLimit is the number of repositories crawled for commits.
+const commitRepoLimit = 20
+
+// Cache states reported in the X-Cache-Status header.
+const (
+	cacheFresh = "fresh"
+	cacheStale = "stale"
+	cacheMiss  = "miss"
+)
+
+// cacheInfo describes where served stats came from.
+type cacheInfo struct {
+	Status     string
+	Age        time.Duration
+	Refreshing bool
+}
+
+// writeCacheHeaders tells clients how old the data is and whether a newer
+// version is being fetched, e.g. "updated 12 minutes ago, refreshing".
+func writeCacheHeaders(w http.ResponseWriter, info cacheInfo) {
+	w.Header().Set("Age", strconv.Itoa(int(info.Age.Seconds())))
+	w.Header().Set("X-Cache-Status", info.Status)
+	w.Header().Set("X-Cache-Refreshing", strconv.FormatBool(info.Refreshing))
+}
+
+// statsCacheKey is the store key for a user's stats and commits. Non-default
+// repository options get their own keys.
+func statsCacheKey(username string, isOwnProfile bool, opts github.RepoOptions) string {
+	if isOwnProfile {
+		return username + ":auth:" + opts.Visibility + opts.Key()
+	}
+	return username + ":" + opts.Visibility + opts.Key()
+}
+
+// commitsCacheKey is the store key for commits. Commits including
+// co-authored ones are kept apart from the default authored-only crawl.
+func commitsCacheKey(cacheKey string, coAuthored bool) string {
+	if coAuthored {
+		return cacheKey + ":coauthored"
+	}
+	return cacheKey
+}
+
+// commitFilter attributes commits to the profile owner by login and public
+// email, so collaborators' and bots' commits in their repos are not counted.
+func commitFilter(username string, profile github.Profile, coAuthored bool) github.CommitFilter {
+	filter := github.CommitFilter{Login: profile.Login, UserID: profile.NodeID, IncludeCoAuthored: coAuthored}
+	if filter.Login == "" {
+		filter.Login = username
+	}
+	if profile.Email != "" {
+		filter.Emails = []string{profile.Email}
+	}
+	return filter
+}
+
+// loadStats returns cached stats or fetches them, sharing a single upstream
+// fetch between concurrent requests for the same cache key. Stats past
+// their TTL but within the grace period are served immediately while one
+// background refresh replaces them.
+func (h *Handler) loadStats(ctx context.Context, client *github.Client, username string, opts github.RepoOptions, cacheKey string) (*github.Stats, cacheInfo, error) {
+	if stats, info := h.cachedStats(client, username, opts, cacheKey); stats != nil {
+		return stats, info, nil
+	}
+
+	stats, err := h.refreshStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		return nil, cacheInfo{}, err
+	}
+	return stats, cacheInfo{Status: cacheMiss, Refreshing: h.isRefreshing(cacheKey)}, nil
+}
+
+// cachedStats returns the stats in the store under cacheKey, or nil, by the
+// rules of loadStats but without fetching on a miss. Stale stats start a
+// background refresh.
+func (h *Handler) cachedStats(client *github.Client, username string, opts github.RepoOptions, cacheKey string) (*github.Stats, cacheInfo) {
+	stats, age := h.store.GetStatsWithAge(cacheKey)
+	if stats == nil {
+		return nil, cacheInfo{}
+	}
+	if age <= cache.StatsCacheTTL {
+		return stats, cacheInfo{Status: cacheFresh, Age: age, Refreshing: h.isRefreshing(cacheKey)}
+	}
+
+	go func() {
+		if _, err := h.refreshStats(context.Background(), client, username, opts, cacheKey); err != nil {
+			log.Printf("Warning: background refresh failed for %s: %v", username, err)
+		}
+	}()
+	return stats, cacheInfo{Status: cacheStale, Age: age, Refreshing: true}
+}
+
+// refreshStats fetches stats once for all concurrent callers and then starts
+// a commit crawl so commits follow the refreshed repository list.
+func (h *Handler) refreshStats(ctx context.Context, client *github.Client, username string, opts github.RepoOptions, cacheKey string) (*github.Stats, error) {
+	v, err := h.flights.Do(ctx, "stats:"+cacheKey, func(ctx context.Context) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+
+		stats, err := client.GetStatsWithOptions(ctx, username, opts)
+		if err != nil {
+			return nil, err
+		}
+		h.store.SetStats(cacheKey, stats)
+		// Only the default public stats match what snapshots measure; the
+		// owner's own public stats also count private contributions.
+		if cacheKey == statsCacheKey(username, false, github.RepoOptions{Visibility: "public"}) {
+			go h.recordSnapshot(username, stats)
+		}
+
+		go func() {
+			if _, err := h.crawlCommits(context.Background(), client, username, cacheKey, stats, false); err != nil {
+				log.Printf("Warning: failed to fetch commits for %s: %v", username, err)
+			}
+		}()
+		return stats, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*github.Stats), nil
+}
+
+// isRefreshing reports whether stats or commits for cacheKey are being fetched.
+func (h *Handler) isRefreshing(cacheKey string) bool {
+	return h.flights.InFlight("stats:"+cacheKey) || h.flights.InFlight("commits:"+cacheKey)
+}
+
+// loadCommits returns cached commits or crawls them, joining a crawl that is
+// already running for the same cache key.
+func (h *Handler) loadCommits(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, coAuthored bool) ([]github.Commit, error) {
+	if commits := h.store.GetCommits(commitsCacheKey(cacheKey, coAuthored)); commits != nil {
+		return commits, nil
+	}
+	return h.crawlCommits(ctx, client, username, cacheKey, stats, coAuthored)
+}
+
+func (h *Handler) crawlCommits(ctx context.Context, client *github.Client, username, cacheKey string, stats *github.Stats, coAuthored bool) ([]github.Commit, error) {
+	key := commitsCacheKey(cacheKey, coAuthored)
+	v, err := h.flights.Do(ctx, "commits:"+key, func(ctx context.Context) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, commitFetchTimeout)
+		defer cancel()
+
+		filter := commitFilter(username, stats.Profile, coAuthored)
+		commits, err := client.GetAllAuthoredCommits(ctx, username, stats.Repositories, commitRepoLimit, filter)
+		if err != nil {
+			return nil, err
+		}
+		if commits == nil {
+			commits = []github.Commit{}
+		}
+		h.store.SetCommits(key, commits)
+		log.Printf("Fetched %d commits for %s", len(commits), username)
+		return commits, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]github.Commit), nil
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// memo caches computed responses that do not fit the per-user store, such
+// as organization stats. Entries expire after ttl and the oldest entry is
+// dropped once maxEntries is reached.
+type memo struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]memoEntry
+}
+
+type memoEntry struct {
+	val any
+	at  time.Time
+}
+
+func newMemo(ttl time.Duration, maxEntries int) *memo {
+	return &memo{ttl: ttl, maxEntries: maxEntries, entries: make(map[string]memoEntry)}
+}
+
+// Get returns the value stored under key and its age, if it has not expired.
+func (m *memo) Get(key string) (any, time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+	age := time.Since(entry.at)
+	if age > m.ttl {
+		delete(m.entries, key)
+		return nil, 0, false
+	}
+	return entry.val, age, true
+}
+
+func (m *memo) Set(key string, val any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.maxEntries {
+		oldestKey, oldest := "", time.Time{}
+		for k, entry := range m.entries {
+			if oldestKey == "" || entry.at.Before(oldest) {
+				oldestKey, oldest = k, entry.at
+			}
+		}
+		delete(m.entries, oldestKey)
+	}
+	m.entries[key] = memoEntry{val: val, at: time.Now()}
+}
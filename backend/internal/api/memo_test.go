@@ -0,0 +1,26 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemo_ExpiresAndEvictsOldest(t *testing.T) {
+	m := newMemo(time.Hour, 2)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.entries["a"] = memoEntry{val: 1, at: time.Now().Add(-time.Minute)}
+	m.Set("c", 3)
+
+	if _, _, ok := m.Get("a"); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	if v, _, ok := m.Get("c"); !ok || v.(int) != 3 {
+		t.Errorf("expected c to be cached, got %v", v)
+	}
+
+	m.entries["b"] = memoEntry{val: 2, at: time.Now().Add(-2 * time.Hour)}
+	if _, _, ok := m.Get("b"); ok {
+		t.Error("expected an expired entry to be missing")
+	}
+}
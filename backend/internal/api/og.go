@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"gh-stats/backend/internal/github"
+	"gh-stats/backend/internal/og"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxCachedImages bounds the rendered preview images kept in memory, at
+// roughly 100 KB each.
+const maxCachedImages = 200
+
+// ogImage is a rendered preview and the stats it was rendered from.
+type ogImage struct {
+	png       []byte
+	updatedAt time.Time
+}
+
+// GetOGImage renders a PNG preview of a user's profile for link previews.
+// Images are cached alongside the stats they show and re-rendered when the
+// stats are refreshed. Previews only ever show public data, so the viewer's
+// session is never used.
+func (h *Handler) GetOGImage(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+
+	client := h.getPublicClient(username)
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	opts := github.RepoOptions{Visibility: "public"}
+	cacheKey := statsCacheKey(username, false, opts)
+	stats, _, err := h.loadStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		log.Printf("get og stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch stats")
+		return
+	}
+
+	if v, _, ok := h.images.Get(cacheKey); ok && v.(ogImage).updatedAt.Equal(stats.UpdatedAt) {
+		writePNG(w, v.(ogImage).png)
+		return
+	}
+
+	v, err := h.flights.Do(ctx, "og:"+cacheKey, func(ctx context.Context) (any, error) {
+		profile := og.Profile{
+			Login:         stats.Profile.Login,
+			Name:          stats.Profile.Name,
+			Streak:        stats.Streak,
+			Languages:     stats.Languages,
+			Contributions: stats.Contributions,
+		}
+		if profile.Login == "" {
+			profile.Login = username
+		}
+		if stats.Profile.AvatarURL != "" {
+			avatar, err := og.FetchAvatar(ctx, stats.Profile.AvatarURL)
+			if err != nil {
+				log.Printf("Warning: failed to fetch avatar for %s: %v", username, err)
+			}
+			profile.Avatar = avatar
+		}
+
+		png, err := og.Render(profile)
+		if err != nil {
+			return nil, err
+		}
+		image := ogImage{png: png, updatedAt: stats.UpdatedAt}
+		h.images.Set(cacheKey, image)
+		return image, nil
+	})
+	if err != nil {
+		log.Printf("render og image error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to render image")
+		return
+	}
+	writePNG(w, v.(ogImage).png)
+}
+
+func writePNG(w http.ResponseWriter, png []byte) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(png)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler_GetOGImage_RendersAndCachesPNG(t *testing.T) {
+	handler := newTestHandler()
+	handler.store.SetStats("testuser:public", &github.Stats{
+		Profile: github.Profile{Login: "testuser", Name: "Test User"},
+		Streak:  github.StreakStats{CurrentStreak: 4, LongestStreak: 30},
+	})
+
+	r := chi.NewRouter()
+	r.Get("/api/og/{username}.png", handler.GetOGImage)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/og/testuser.png", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("expected image/png, got %q", ct)
+	}
+	if _, err := png.Decode(bytes.NewReader(w.Body.Bytes())); err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if _, _, ok := handler.images.Get("testuser:public"); !ok {
+		t.Error("expected the image to be cached under the stats key")
+	}
+}
+
+func TestHandler_GetOGImage_IgnoresViewerSession(t *testing.T) {
+	tokens := tokensUsedWithSession(t, "/api/og/{username}.png", "/api/og/testuser.png",
+		func(h *Handler) http.HandlerFunc { return h.GetOGImage })
+
+	if len(tokens) == 0 {
+		t.Fatal("expected the preview to fetch stats")
+	}
+	for _, token := range tokens {
+		if token != "Bearer server-token" {
+			t.Errorf("expected previews to be fetched with the server token, got %q", token)
+		}
+	}
+}
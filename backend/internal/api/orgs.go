@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gh-stats/backend/internal/github"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// maxCachedOrgs bounds the organization dashboards kept in memory.
+	maxCachedOrgs = 200
+	// orgCommitWindow is how far back organization commits are crawled, so
+	// the crawl of large, old repositories stays bounded.
+	orgCommitWindow = 90 * 24 * time.Hour
+)
+
+// orgActivity is a cached organization dashboard together with the member
+// commits it was built from, so fun stats can be computed per timezone.
+type orgActivity struct {
+	stats   *github.OrgStats
+	commits []github.Commit
+}
+
+// GetOrgStats aggregates activity across an organization's members and
+// repositories. The team parameter narrows the members to a team's.
+func (h *Handler) GetOrgStats(w http.ResponseWriter, r *http.Request) {
+	org := chi.URLParam(r, "org")
+	if org == "" {
+		http.Error(w, "org required", http.StatusBadRequest)
+		return
+	}
+	team := r.URL.Query().Get("team")
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	client := h.getClientForUser(r, org)
+	ctx, cancel := context.WithTimeout(r.Context(), commitFetchTimeout)
+	defer cancel()
+
+	// Members and repositories visible to a signed-in member may be private,
+	// so their dashboards are cached apart from the public one.
+	cacheKey := "org:" + strings.ToLower(org) + ":" + strings.ToLower(team)
+	if session := h.getSession(r); session != nil {
+		cacheKey += ":auth:" + session.Username
+	}
+
+	info := cacheInfo{Status: cacheFresh}
+	v, age, ok := h.orgs.Get(cacheKey)
+	if ok {
+		info.Age = age
+	} else {
+		info.Status = cacheMiss
+		v, err = h.flights.Do(ctx, cacheKey, func(ctx context.Context) (any, error) {
+			activity, err := fetchOrgActivity(ctx, client, org, team)
+			if err != nil {
+				return nil, err
+			}
+			h.orgs.Set(cacheKey, activity)
+			return activity, nil
+		})
+		if err != nil {
+			log.Printf("get org stats error for %s: %v", org, err)
+			writeError(w, err, "organization or team not found", "failed to fetch organization stats")
+			return
+		}
+	}
+
+	activity := v.(*orgActivity)
+	stats := *activity.stats
+	stats.FunStats = computeFunStats(localizeCommits(activity.commits, loc), len(stats.Repositories))
+	stats.FunStats.Timezone = loc.String()
+
+	writeCacheHeaders(w, info)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// fetchOrgActivity collects an organization's repositories and members and
+// crawls the recent commits of its most starred repositories. Code frequency and
+// language sizes are best effort and left out when they fail.
+func fetchOrgActivity(ctx context.Context, client *github.Client, org, team string) (*orgActivity, error) {
+	organization, err := client.GetOrganization(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []github.Profile
+	if team != "" {
+		members, err = client.GetTeamMembers(ctx, org, team)
+	} else {
+		members, err = client.GetOrgMembers(ctx, org)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := client.GetOrgRepositories(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	if repos == nil {
+		repos = []github.Repository{}
+	}
+
+	sampled := make([]github.Repository, len(repos))
+	copy(sampled, repos)
+	sort.SliceStable(sampled, func(i, j int) bool { return sampled[i].Stars > sampled[j].Stars })
+	if len(sampled) > commitRepoLimit {
+		sampled = sampled[:commitRepoLimit]
+	}
+
+	since := time.Now().Add(-orgCommitWindow).UTC().Truncate(24 * time.Hour)
+	commits, err := client.GetAllAuthoredCommits(ctx, org, sampled, commitRepoLimit, github.CommitFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	commits = memberCommits(commits, members)
+
+	codeFrequency, err := client.GetCodeFrequency(ctx, org, sampled)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("get code frequency error for org %s: %v", org, err)
+	}
+	languagesByBytes, err := client.GetRepoLanguageBytes(ctx, org, sampled)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("get language sizes error for org %s: %v", org, err)
+	}
+	// Languages are counted locally: the GraphQL color lookup used for users
+	// finds nothing for an organization.
+	languages := github.CountLanguages(repos, nil)
+	if languages == nil {
+		languages = []github.LanguageStats{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &github.OrgStats{
+		Organization:     *organization,
+		Team:             team,
+		Members:          len(members),
+		TotalCommits:     len(commits),
+		ReposSampled:     len(sampled),
+		CommitsSince:     since,
+		Repositories:     repos,
+		Languages:        languages,
+		LanguagesByBytes: languagesByBytes,
+		CodeFrequency:    codeFrequency,
+		Leaderboard:      buildLeaderboard(members, commits),
+		RepoActivity:     buildRepoActivity(sampled, commits),
+		UpdatedAt:        time.Now(),
+	}
+	for _, repo := range repos {
+		stats.TotalStars += repo.Stars
+		stats.TotalForks += repo.Forks
+	}
+	return &orgActivity{stats: stats, commits: commits}, nil
+}
+
+// memberCommits keeps the commits authored by one of members.
+func memberCommits(commits []github.Commit, members []github.Profile) []github.Commit {
+	logins := make(map[string]bool, len(members))
+	for _, m := range members {
+		logins[strings.ToLower(m.Login)] = true
+	}
+	kept := make([]github.Commit, 0, len(commits))
+	for _, c := range commits {
+		if logins[strings.ToLower(c.AuthorLogin)] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// buildLeaderboard ranks members by commits, then lines changed. Members
+// without commits are listed last so the whole team appears.
+func buildLeaderboard(members []github.Profile, commits []github.Commit) []github.OrgMemberStats {
+	byLogin := make(map[string]*github.OrgMemberStats, len(members))
+	repos := make(map[string]map[string]bool, len(members))
+	leaderboard := make([]github.OrgMemberStats, len(members))
+	for i, m := range members {
+		leaderboard[i] = github.OrgMemberStats{Login: m.Login, AvatarURL: m.AvatarURL}
+		byLogin[strings.ToLower(m.Login)] = &leaderboard[i]
+		repos[strings.ToLower(m.Login)] = make(map[string]bool)
+	}
+
+	for _, c := range commits {
+		login := strings.ToLower(c.AuthorLogin)
+		member, ok := byLogin[login]
+		if !ok {
+			continue
+		}
+		member.Commits++
+		member.Additions += c.Additions
+		member.Deletions += c.Deletions
+		repos[login][c.Repo] = true
+		if member.LastCommit == nil || c.Date.After(*member.LastCommit) {
+			member.LastCommit = &c.Date
+		}
+	}
+	for i := range leaderboard {
+		leaderboard[i].Repositories = len(repos[strings.ToLower(leaderboard[i].Login)])
+	}
+
+	sort.SliceStable(leaderboard, func(i, j int) bool {
+		a, b := leaderboard[i], leaderboard[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return strings.ToLower(a.Login) < strings.ToLower(b.Login)
+	})
+	return leaderboard
+}
+
+// buildRepoActivity summarizes member commits per crawled repository, most
+// active first.
+func buildRepoActivity(repos []github.Repository, commits []github.Commit) []github.OrgRepoActivity {
+	byName := make(map[string]*github.OrgRepoActivity, len(repos))
+	contributors := make(map[string]map[string]bool, len(repos))
+	activity := make([]github.OrgRepoActivity, len(repos))
+	for i, repo := range repos {
+		activity[i] = github.OrgRepoActivity{Name: repo.Name, Language: repo.Language, Stars: repo.Stars}
+		byName[strings.ToLower(repo.Key())] = &activity[i]
+		contributors[strings.ToLower(repo.Key())] = make(map[string]bool)
+	}
+
+	for _, c := range commits {
+		key := strings.ToLower(c.Repo)
+		repo, ok := byName[key]
+		if !ok {
+			continue
+		}
+		repo.Commits++
+		repo.Additions += c.Additions
+		repo.Deletions += c.Deletions
+		contributors[key][strings.ToLower(c.AuthorLogin)] = true
+		if repo.LastCommit == nil || c.Date.After(*repo.LastCommit) {
+			repo.LastCommit = &c.Date
+		}
+	}
+	for i := range activity {
+		activity[i].Contributors = len(contributors[strings.ToLower(repos[i].Key())])
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		if activity[i].Commits != activity[j].Commits {
+			return activity[i].Commits > activity[j].Commits
+		}
+		return activity[i].Stars > activity[j].Stars
+	})
+	return activity
+}
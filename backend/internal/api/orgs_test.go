@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestBuildLeaderboard_RanksMembersByCommits(t *testing.T) {
+	day := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	members := []github.Profile{{Login: "alice"}, {Login: "Bob"}, {Login: "carol"}}
+	commits := []github.Commit{
+		{AuthorLogin: "bob", Repo: "api", Date: day, Additions: 10},
+		{AuthorLogin: "bob", Repo: "web", Date: day.Add(time.Hour), Additions: 5},
+		{AuthorLogin: "alice", Repo: "api", Date: day, Additions: 100},
+		{AuthorLogin: "outsider", Repo: "api", Date: day},
+	}
+
+	leaderboard := buildLeaderboard(members, memberCommits(commits, members))
+
+	if len(leaderboard) != 3 {
+		t.Fatalf("expected every member listed, got %+v", leaderboard)
+	}
+	bob := leaderboard[0]
+	if bob.Login != "Bob" || bob.Commits != 2 || bob.Repositories != 2 || bob.LastCommit == nil || !bob.LastCommit.Equal(day.Add(time.Hour)) {
+		t.Errorf("unexpected leader: %+v", bob)
+	}
+	if leaderboard[1].Login != "alice" || leaderboard[1].Additions != 100 {
+		t.Errorf("unexpected second place: %+v", leaderboard[1])
+	}
+	if leaderboard[2].Login != "carol" || leaderboard[2].Commits != 0 || leaderboard[2].LastCommit != nil {
+		t.Errorf("expected carol last without commits, got %+v", leaderboard[2])
+	}
+}
+
+func TestBuildRepoActivity_CountsContributors(t *testing.T) {
+	repos := []github.Repository{{Name: "docs", Stars: 50}, {Name: "api", Stars: 10, Language: "Go"}}
+	commits := []github.Commit{
+		{AuthorLogin: "alice", Repo: "api", Deletions: 3},
+		{AuthorLogin: "bob", Repo: "API", Additions: 7},
+		{AuthorLogin: "alice", Repo: "api"},
+	}
+
+	activity := buildRepoActivity(repos, commits)
+
+	if activity[0].Name != "api" || activity[0].Commits != 3 || activity[0].Contributors != 2 {
+		t.Errorf("expected api first with 3 commits by 2 contributors, got %+v", activity[0])
+	}
+	if activity[0].Additions != 7 || activity[0].Deletions != 3 || activity[0].Language != "Go" {
+		t.Errorf("unexpected api line counts: %+v", activity[0])
+	}
+	if activity[1].Name != "docs" || activity[1].Commits != 0 || activity[1].Contributors != 0 || activity[1].LastCommit != nil {
+		t.Errorf("expected docs without activity, got %+v", activity[1])
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+// parseTimezone reads the optional tz query parameter, an IANA zone name such
+// as "Asia/Tokyo". It returns nil when the parameter is absent; dates such as
+// streaks and calendars then default to UTC, and commit habits to the zone
+// inferred from the commits.
+func parseTimezone(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tz %q: expected an IANA timezone such as Europe/Vilnius", name)
+	}
+	return loc, nil
+}
+
+// inferTimezone guesses a user's timezone from the most common UTC offset
+// their commits were authored with. Commits listed over REST carry UTC
+// timestamps, so the guess is UTC unless offsets are known.
+func inferTimezone(commits []github.Commit) *time.Location {
+	counts := make(map[int]int)
+	for _, c := range commits {
+		_, offset := c.Date.Zone()
+		counts[offset]++
+	}
+
+	best, bestCount := 0, 0
+	for offset, count := range counts {
+		if count > bestCount || (count == bestCount && offset < best) {
+			best, bestCount = offset, count
+		}
+	}
+	if best == 0 {
+		return time.UTC
+	}
+
+	sign := '+'
+	abs := best
+	if best < 0 {
+		sign = '-'
+		abs = -best
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%c%02d:%02d", sign, abs/3600, abs%3600/60), best)
+}
+
+// localizeCommits returns commits with their dates converted to loc.
+func localizeCommits(commits []github.Commit, loc *time.Location) []github.Commit {
+	localized := make([]github.Commit, len(commits))
+	for i, c := range commits {
+		c.Date = c.Date.In(loc)
+		localized[i] = c
+	}
+	return localized
+}
+
+// streakIn decides whether the cached streak is still current using today
+// in loc instead of server time. The cached lengths come from the lifetime
+// calendar, so they are kept unless the streak has ended in loc.
+func streakIn(stats *github.Stats, loc *time.Location) github.StreakStats {
+	streak := github.StreakAt(stats.Contributions, stats.Streak.TotalContributions, time.Now().In(loc))
+	streak.LongestStreak = max(streak.LongestStreak, stats.Streak.LongestStreak)
+	if streak.CurrentStreak > 0 {
+		streak.CurrentStreak = max(streak.CurrentStreak, stats.Streak.CurrentStreak)
+	}
+	return streak
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestParseTimezone(t *testing.T) {
+	loc, err := parseTimezone(httptest.NewRequest("GET", "/?tz=Asia/Tokyo", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.String() != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo, got %s", loc)
+	}
+
+	if loc, err := parseTimezone(httptest.NewRequest("GET", "/", nil)); loc != nil || err != nil {
+		t.Errorf("expected nil location without tz, got %v, %v", loc, err)
+	}
+	if _, err := parseTimezone(httptest.NewRequest("GET", "/?tz=Mars/Olympus", nil)); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+func TestInferTimezone_UsesMostCommonOffset(t *testing.T) {
+	tokyo := time.FixedZone("", 9*3600)
+	commits := []github.Commit{
+		{Date: time.Date(2025, 1, 1, 10, 0, 0, 0, tokyo)},
+		{Date: time.Date(2025, 1, 2, 10, 0, 0, 0, tokyo)},
+		{Date: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+	}
+
+	loc := inferTimezone(commits)
+	if _, offset := time.Now().In(loc).Zone(); offset != 9*3600 {
+		t.Errorf("expected +09:00 offset, got %d", offset)
+	}
+	if loc.String() != "UTC+09:00" {
+		t.Errorf("expected zone name UTC+09:00, got %s", loc)
+	}
+	if inferTimezone(nil) != time.UTC {
+		t.Error("expected UTC without commits")
+	}
+}
+
+func TestComputeFunStats_BucketsInTimezone(t *testing.T) {
+	// 14:00 UTC is 23:00 in Tokyo.
+	commits := []github.Commit{
+		{Date: time.Date(2025, 1, 3, 14, 0, 0, 0, time.UTC), Repo: "repo"},
+		{Date: time.Date(2025, 1, 4, 14, 0, 0, 0, time.UTC), Repo: "repo"},
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	utcStats := computeFunStats(commits, 1)
+	tokyoStats := computeFunStats(localizeCommits(commits, tokyo), 1)
+
+	if utcStats.NightOwlPercent != 0 {
+		t.Errorf("expected no night commits in UTC, got %.0f%%", utcStats.NightOwlPercent)
+	}
+	if tokyoStats.NightOwlPercent != 100 {
+		t.Errorf("expected all night commits in Tokyo, got %.0f%%", tokyoStats.NightOwlPercent)
+	}
+	if tokyoStats.MostProductiveHour != 23 {
+		t.Errorf("expected most productive hour 23, got %d", tokyoStats.MostProductiveHour)
+	}
+}
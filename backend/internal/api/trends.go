@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gh-stats/backend/internal/github"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// trendLanguages is the number of languages charted before the rest is
+// grouped as "Other".
+const trendLanguages = 8
+
+// languageWeight is the weight a commit contributes to a language in its month.
+type languageWeight struct {
+	month    string
+	language string
+	weight   float64
+}
+
+func (h *Handler) GetUserLanguageTrends(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+
+	visibility := r.URL.Query().Get("visibility")
+	if visibility == "" {
+		visibility = "public"
+	}
+	opts, err := parseRepoOptions(r, visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	weight := r.URL.Query().Get("weight")
+	if weight == "" {
+		weight = "commits"
+	}
+	if weight != "commits" && weight != "lines" {
+		http.Error(w, "weight must be commits or lines", http.StatusBadRequest)
+		return
+	}
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "repo"
+	}
+	if source != "repo" && source != "files" {
+		http.Error(w, "source must be repo or files", http.StatusBadRequest)
+		return
+	}
+	loc, err := parseTimezone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	client := h.getClientForUser(r, username)
+	ctx, cancel := context.WithTimeout(r.Context(), commitFetchTimeout)
+	defer cancel()
+	session := h.getSession(r)
+	isOwnProfile := session != nil && strings.EqualFold(session.Username, username)
+
+	if (visibility == "private" || visibility == "all") && !isOwnProfile {
+		http.Error(w, "private visibility only available for your own profile", http.StatusForbidden)
+		return
+	}
+
+	cacheKey := statsCacheKey(username, isOwnProfile, opts)
+
+	stats, info, err := h.loadStats(ctx, client, username, opts, cacheKey)
+	if err != nil {
+		log.Printf("get stats error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch stats")
+		return
+	}
+
+	commits, err := h.loadCommits(ctx, client, username, cacheKey, stats, false)
+	if err != nil {
+		log.Printf("get commits error for %s: %v", username, err)
+		writeError(w, err, "user not found", "failed to fetch commits")
+		return
+	}
+	commits = localizeCommits(commits, loc)
+
+	var weights []languageWeight
+	covered, fallback := len(commits), false
+	if source == "files" {
+		files, err := client.GetFilesForCommits(ctx, username, commits)
+		if err != nil {
+			log.Printf("get commit files error for %s: %v", username, err)
+			writeError(w, err, "user not found", "failed to fetch commit files")
+			return
+		}
+		weights = fileWeights(commits, files, weight == "lines")
+		covered = len(files)
+	} else {
+		// Commits crawled over REST carry no line counts.
+		if weight == "lines" && !hasLineCounts(commits) {
+			weight, fallback = "commits", true
+		}
+		weights = repoWeights(commits, stats.Repositories, weight == "lines")
+	}
+
+	trends := buildLanguageTrends(weights, languageColors(stats))
+	trends.Weight = weight
+	trends.WeightFallback = fallback
+	trends.Source = source
+	trends.Commits = covered
+	trends.TotalCommits = len(commits)
+
+	writeCacheHeaders(w, info)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trends)
+}
+
+// hasLineCounts reports whether any commit carries line counts.
+func hasLineCounts(commits []github.Commit) bool {
+	for _, c := range commits {
+		if c.Additions+c.Deletions > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// repoWeights attributes each commit to the primary language of its repo.
+func repoWeights(commits []github.Commit, repos []github.Repository, byLines bool) []languageWeight {
+	repoLanguages := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		repoLanguages[strings.ToLower(repo.Key())] = repo.Language
+	}
+
+	var weights []languageWeight
+	for _, c := range commits {
+		language := repoLanguages[strings.ToLower(c.Repo)]
+		if language == "" {
+			continue
+		}
+		weight := 1.0
+		if byLines {
+			weight = float64(c.Additions + c.Deletions)
+		}
+		if weight > 0 {
+			weights = append(weights, languageWeight{c.Date.Format("2006-01"), language, weight})
+		}
+	}
+	return weights
+}
+
+// fileWeights attributes commits to the languages of the files they
+// changed. By commits, each commit's single vote is split by lines changed
+// per language; by lines, the lines changed are counted directly.
+func fileWeights(commits []github.Commit, files map[string][]github.CommitFile, byLines bool) []languageWeight {
+	var weights []languageWeight
+	for _, c := range commits {
+		changed, ok := files[c.SHA]
+		if !ok {
+			continue
+		}
+
+		lines := make(map[string]float64)
+		var total float64
+		for _, f := range changed {
+			language := github.LanguageForFile(f.Filename)
+			if language == "" {
+				continue
+			}
+			n := float64(max(f.Additions+f.Deletions, 1))
+			lines[language] += n
+			total += n
+		}
+
+		month := c.Date.Format("2006-01")
+		for language, n := range lines {
+			weight := n
+			if !byLines {
+				weight = n / total
+			}
+			weights = append(weights, languageWeight{month, language, weight})
+		}
+	}
+	return weights
+}
+
+// languageColors returns the colors GitHub uses for the user's languages.
+func languageColors(stats *github.Stats) map[string]string {
+	colors := make(map[string]string)
+	for _, lang := range stats.Languages {
+		colors[lang.Name] = lang.Color
+	}
+	for _, lang := range stats.LanguagesByBytes {
+		colors[lang.Name] = lang.Color
+	}
+	return colors
+}
+
+// buildLanguageTrends turns weights into one series per language over every
+// month from the first to the last with data, so series line up for a
+// stacked area chart. Shares of a month add up to 100.
+func buildLanguageTrends(weights []languageWeight, colors map[string]string) github.LanguageTrends {
+	trends := github.LanguageTrends{Months: []string{}, Series: []github.LanguageTrend{}}
+	if len(weights) == 0 {
+		return trends
+	}
+
+	totals := make(map[string]float64)
+	first, last := weights[0].month, weights[0].month
+	for _, lw := range weights {
+		totals[lw.language] += lw.weight
+		first = min(first, lw.month)
+		last = max(last, lw.month)
+	}
+
+	languages := make([]string, 0, len(totals))
+	for language := range totals {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if totals[languages[i]] != totals[languages[j]] {
+			return totals[languages[i]] > totals[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+
+	series := make(map[string]int)
+	for i, language := range languages {
+		if i == trendLanguages {
+			break
+		}
+		series[language] = i
+	}
+	if len(languages) > trendLanguages {
+		series["Other"] = trendLanguages
+		languages = append(languages[:trendLanguages], "Other")
+	}
+
+	start, _ := time.Parse("2006-01", first)
+	end, _ := time.Parse("2006-01", last)
+	monthIndex := make(map[string]int)
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		monthIndex[m.Format("2006-01")] = len(trends.Months)
+		trends.Months = append(trends.Months, m.Format("2006-01"))
+	}
+
+	for _, language := range languages {
+		color := colors[language]
+		if color == "" {
+			color = github.LanguageColor(language)
+		}
+		trends.Series = append(trends.Series, github.LanguageTrend{
+			Language: language,
+			Color:    color,
+			Shares:   make([]float64, len(trends.Months)),
+			Totals:   make([]float64, len(trends.Months)),
+		})
+	}
+
+	monthTotals := make([]float64, len(trends.Months))
+	for _, lw := range weights {
+		i, ok := series[lw.language]
+		if !ok {
+			i = series["Other"]
+		}
+		m := monthIndex[lw.month]
+		trends.Series[i].Totals[m] += lw.weight
+		monthTotals[m] += lw.weight
+	}
+
+	for _, s := range trends.Series {
+		for m, total := range monthTotals {
+			if total > 0 {
+				s.Shares[m] = math.Round(s.Totals[m]*1000/total) / 10
+			}
+		}
+	}
+	return trends
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestBuildLanguageTrends_FillsMonthsAndSharesSumTo100(t *testing.T) {
+	weights := []languageWeight{
+		{"2023-01", "Python", 3},
+		{"2023-01", "Go", 1},
+		{"2023-03", "Go", 3},
+	}
+
+	trends := buildLanguageTrends(weights, map[string]string{"Go": "#00ADD8"})
+
+	wantMonths := []string{"2023-01", "2023-02", "2023-03"}
+	if len(trends.Months) != len(wantMonths) {
+		t.Fatalf("expected months %v, got %v", wantMonths, trends.Months)
+	}
+	for i, m := range wantMonths {
+		if trends.Months[i] != m {
+			t.Errorf("month %d = %s, want %s", i, trends.Months[i], m)
+		}
+	}
+
+	if len(trends.Series) != 2 || trends.Series[0].Language != "Go" {
+		t.Fatalf("expected Go then Python series, got %+v", trends.Series)
+	}
+	goSeries := trends.Series[0]
+	if goSeries.Color != "#00ADD8" {
+		t.Errorf("expected Go color from stats, got %s", goSeries.Color)
+	}
+	if goSeries.Shares[0] != 25 || goSeries.Shares[1] != 0 || goSeries.Shares[2] != 100 {
+		t.Errorf("unexpected Go shares: %v", goSeries.Shares)
+	}
+	if trends.Series[1].Shares[0]+goSeries.Shares[0] != 100 {
+		t.Errorf("expected shares of a month to sum to 100")
+	}
+}
+
+func TestRepoWeights_UsesPrimaryLanguage(t *testing.T) {
+	commits := []github.Commit{
+		{Repo: "api", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Additions: 10, Deletions: 5},
+		{Repo: "docs", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	repos := []github.Repository{{Name: "api", Language: "Go"}, {Name: "docs"}}
+
+	byCommits := repoWeights(commits, repos, false)
+	if len(byCommits) != 1 || byCommits[0].language != "Go" || byCommits[0].weight != 1 {
+		t.Errorf("unexpected commit weights: %+v", byCommits)
+	}
+	byLines := repoWeights(commits, repos, true)
+	if len(byLines) != 1 || byLines[0].weight != 15 {
+		t.Errorf("unexpected line weights: %+v", byLines)
+	}
+}
+
+func TestFileWeights_SplitsCommitAcrossLanguages(t *testing.T) {
+	commits := []github.Commit{{SHA: "a", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}}
+	files := map[string][]github.CommitFile{"a": {
+		{Filename: "main.go", Additions: 30},
+		{Filename: "deploy/app.yaml", Additions: 5, Deletions: 5},
+		{Filename: "LICENSE", Additions: 100},
+	}}
+
+	weights := fileWeights(commits, files, false)
+
+	byLanguage := make(map[string]float64)
+	for _, lw := range weights {
+		byLanguage[lw.language] = lw.weight
+	}
+	if byLanguage["Go"] != 0.75 || byLanguage["YAML"] != 0.25 {
+		t.Errorf("expected the commit split 75/25 between Go and YAML, got %v", byLanguage)
+	}
+}
+
+func TestHasLineCounts(t *testing.T) {
+	if hasLineCounts([]github.Commit{{SHA: "a"}, {SHA: "b"}}) {
+		t.Error("expected REST commits without line counts to have none")
+	}
+	if !hasLineCounts([]github.Commit{{SHA: "a"}, {SHA: "b", Deletions: 3}}) {
+		t.Error("expected line counts to be found")
+	}
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"container/list"
 	"crypto/rand"
 	"encoding/hex"
 	"sync"
@@ -11,34 +12,153 @@ import (
 
 const (
 	StatsCacheTTL = 10 * time.Minute
+	// StaleGracePeriod is how long past StatsCacheTTL entries may still be
+	// served while a background refresh replaces them.
+	StaleGracePeriod = 50 * time.Minute
 )
 
+// Backend is the storage used by the API handlers for cached stats,
+// commits, OAuth states and sessions.
+type Backend interface {
+	GetUserData(username string) *UserData
+	GetStats(username string) *github.Stats
+	GetStatsWithAge(username string) (*github.Stats, time.Duration)
+	SetStats(username string, stats *github.Stats)
+	GetCommits(username string) []github.Commit
+	SetCommits(username string, commits []github.Commit)
+	IsStale(username string, maxAge time.Duration) bool
+	CreateState() string
+	ValidateState(state string) bool
+	CreateSession(username, accessToken, avatarURL string) *github.Session
+	GetSession(id string) *github.Session
+	DeleteSession(id string)
+	Close() error
+}
+
 type UserData struct {
 	Stats     *github.Stats
 	Commits   []github.Commit
 	UpdatedAt time.Time
+
+	elem *list.Element
+	size int64
 }
 
+// Limits bounds the memory held by cached user data. When either limit is
+// exceeded the least recently used entries are evicted. Zero disables a
+// limit.
+type Limits struct {
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// DefaultLimits keeps the cache well below the memory of a small container.
+var DefaultLimits = Limits{MaxEntries: 2000, MaxBytes: 256 << 20}
+
+// Store is the in-memory Backend.
 type Store struct {
 	mu       sync.RWMutex
 	users    map[string]*UserData
 	sessions map[string]*github.Session
 	states   map[string]time.Time
+	done     chan struct{}
+	closed   sync.Once
+
+	limits Limits
+	lru    *list.List
+	bytes  int64
 }
 
 func New() *Store {
+	return NewWithLimits(DefaultLimits)
+}
+
+// NewWithLimits creates a Store that evicts user data beyond limits.
+func NewWithLimits(limits Limits) *Store {
 	store := &Store{
 		users:    make(map[string]*UserData),
 		sessions: make(map[string]*github.Session),
 		states:   make(map[string]time.Time),
+		done:     make(chan struct{}),
+		limits:   limits,
+		lru:      list.New(),
 	}
 	go store.cleanupExpired()
 	return store
 }
 
+// Usage reports the number of cached users and their estimated size in bytes.
+func (s *Store) Usage() (entries int, bytes int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users), s.bytes
+}
+
+// touch marks key as most recently used. Callers must hold s.mu.
+func (s *Store) touch(key string, data *UserData) {
+	if data.elem == nil {
+		data.elem = s.lru.PushFront(key)
+		return
+	}
+	s.lru.MoveToFront(data.elem)
+}
+
+// resize recomputes the size of an entry after it changed and evicts least
+// recently used entries until the store is within its limits. Callers must
+// hold s.mu.
+func (s *Store) resize(key string, data *UserData) {
+	s.bytes -= data.size
+	data.size = sizeOf(key, data)
+	s.bytes += data.size
+	s.touch(key, data)
+	s.evict()
+}
+
+func (s *Store) evict() {
+	for {
+		overEntries := s.limits.MaxEntries > 0 && s.lru.Len() > s.limits.MaxEntries
+		overBytes := s.limits.MaxBytes > 0 && s.bytes > s.limits.MaxBytes
+		if !overEntries && !overBytes {
+			return
+		}
+		oldest := s.lru.Back()
+		if oldest == nil {
+			return
+		}
+		s.remove(oldest.Value.(string))
+	}
+}
+
+// remove deletes a user entry and its accounting. Callers must hold s.mu.
+func (s *Store) remove(key string) {
+	data, ok := s.users[key]
+	if !ok {
+		return
+	}
+	if data.elem != nil {
+		s.lru.Remove(data.elem)
+		data.elem = nil
+	}
+	s.bytes -= data.size
+	data.size = 0
+	delete(s.users, key)
+}
+
+// Close stops the background cleanup.
+func (s *Store) Close() error {
+	s.closed.Do(func() { close(s.done) })
+	return nil
+}
+
 func (s *Store) cleanupExpired() {
 	ticker := time.NewTicker(5 * time.Minute)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
 		s.mu.Lock()
 		now := time.Now()
 		for id, session := range s.sessions {
@@ -52,8 +172,8 @@ func (s *Store) cleanupExpired() {
 			}
 		}
 		for key, data := range s.users {
-			if now.Sub(data.UpdatedAt) > StatsCacheTTL {
-				delete(s.users, key)
+			if now.Sub(data.UpdatedAt) > StatsCacheTTL+StaleGracePeriod {
+				s.remove(key)
 			}
 		}
 		s.mu.Unlock()
@@ -61,22 +181,41 @@ func (s *Store) cleanupExpired() {
 }
 
 func (s *Store) GetUserData(username string) *UserData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.users[username]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.users[username]
+	if ok {
+		s.touch(username, data)
+	}
+	return data
 }
 
 func (s *Store) GetStats(username string) *github.Stats {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if data, ok := s.users[username]; ok {
 		if time.Since(data.UpdatedAt) <= StatsCacheTTL {
+			s.touch(username, data)
 			return data.Stats
 		}
 	}
 	return nil
 }
 
+// GetStatsWithAge returns stats that are at most StatsCacheTTL plus
+// StaleGracePeriod old, together with their age.
+func (s *Store) GetStatsWithAge(username string) (*github.Stats, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data, ok := s.users[username]; ok && data.Stats != nil {
+		if age := time.Since(data.UpdatedAt); age <= StatsCacheTTL+StaleGracePeriod {
+			s.touch(username, data)
+			return data.Stats, age
+		}
+	}
+	return nil, 0
+}
+
 func (s *Store) SetStats(username string, stats *github.Stats) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -85,13 +224,17 @@ func (s *Store) SetStats(username string, stats *github.Stats) {
 	}
 	s.users[username].Stats = stats
 	s.users[username].UpdatedAt = time.Now()
+	s.resize(username, s.users[username])
 }
 
+// GetCommits returns commits for stats that are fresh or still within the
+// grace period. Commits are replaced by the crawl following a refresh.
 func (s *Store) GetCommits(username string) []github.Commit {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if data, ok := s.users[username]; ok {
-		if time.Since(data.UpdatedAt) <= StatsCacheTTL {
+		if time.Since(data.UpdatedAt) <= StatsCacheTTL+StaleGracePeriod {
+			s.touch(username, data)
 			return data.Commits
 		}
 	}
@@ -105,6 +248,7 @@ func (s *Store) SetCommits(username string, commits []github.Commit) {
 		s.users[username] = &UserData{UpdatedAt: time.Now()}
 	}
 	s.users[username].Commits = commits
+	s.resize(username, s.users[username])
 }
 
 func (s *Store) IsStale(username string, maxAge time.Duration) bool {
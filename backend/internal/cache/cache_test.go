@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -248,3 +249,101 @@ func TestStore_GetUserData(t *testing.T) {
 		t.Errorf("expected 1 commit, got %d", len(data.Commits))
 	}
 }
+
+func TestStore_GetStatsWithAge_ServesStaleWithinGracePeriod(t *testing.T) {
+	store := New()
+	store.mu.Lock()
+	store.users["testuser"] = &UserData{
+		Stats:     &github.Stats{},
+		Commits:   []github.Commit{{SHA: "abc"}},
+		UpdatedAt: time.Now().Add(-StatsCacheTTL - time.Minute),
+	}
+	store.mu.Unlock()
+
+	if store.GetStats("testuser") != nil {
+		t.Error("expected GetStats to ignore stale data")
+	}
+	stats, age := store.GetStatsWithAge("testuser")
+	if stats == nil {
+		t.Fatal("expected stale stats within grace period")
+	}
+	if age <= StatsCacheTTL {
+		t.Errorf("expected age past TTL, got %v", age)
+	}
+	if len(store.GetCommits("testuser")) != 1 {
+		t.Error("expected stale commits within grace period")
+	}
+}
+
+func TestStore_GetStatsWithAge_DropsDataPastGracePeriod(t *testing.T) {
+	store := New()
+	store.mu.Lock()
+	store.users["testuser"] = &UserData{
+		Stats:     &github.Stats{},
+		UpdatedAt: time.Now().Add(-StatsCacheTTL - StaleGracePeriod - time.Minute),
+	}
+	store.mu.Unlock()
+
+	if stats, _ := store.GetStatsWithAge("testuser"); stats != nil {
+		t.Error("expected nil past the grace period")
+	}
+}
+
+func TestStore_EvictsLeastRecentlyUsedBeyondMaxEntries(t *testing.T) {
+	store := NewWithLimits(Limits{MaxEntries: 2})
+	defer store.Close()
+
+	store.SetStats("a", &github.Stats{})
+	store.SetStats("b", &github.Stats{})
+	store.GetStats("a")
+	store.SetStats("c", &github.Stats{})
+
+	if store.GetStats("b") != nil {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if store.GetStats("a") == nil || store.GetStats("c") == nil {
+		t.Error("expected recently used entries to be kept")
+	}
+	if entries, _ := store.Usage(); entries != 2 {
+		t.Errorf("expected 2 entries, got %d", entries)
+	}
+}
+
+func TestStore_EvictsBeyondMaxBytes(t *testing.T) {
+	commits := make([]github.Commit, 100)
+	for i := range commits {
+		commits[i] = github.Commit{SHA: strings.Repeat("a", 40), Message: strings.Repeat("m", 200)}
+	}
+	one := sizeOf("a", &UserData{Commits: commits})
+
+	store := NewWithLimits(Limits{MaxBytes: one*2 + one/2})
+	defer store.Close()
+
+	store.SetCommits("a", commits)
+	store.SetCommits("b", commits)
+	store.SetCommits("c", commits)
+
+	if store.GetCommits("a") != nil {
+		t.Error("expected oldest entry to be evicted")
+	}
+	if store.GetCommits("c") == nil {
+		t.Error("expected newest entry to be kept")
+	}
+	if _, bytes := store.Usage(); bytes > one*2+one/2 {
+		t.Errorf("expected usage within budget, got %d bytes", bytes)
+	}
+}
+
+func TestStore_SizeAccountingTracksReplacements(t *testing.T) {
+	store := NewWithLimits(Limits{})
+	defer store.Close()
+
+	store.SetCommits("a", make([]github.Commit, 1000))
+	_, large := store.Usage()
+	store.SetCommits("a", nil)
+	_, small := store.Usage()
+
+	if small >= large {
+		t.Errorf("expected size to shrink after replacing commits, got %d >= %d", small, large)
+	}
+}
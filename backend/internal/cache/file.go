@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gh-stats/backend/internal/github"
+)
+
+// fileFlushInterval is how often a dirty FileStore writes its snapshot.
+const fileFlushInterval = 30 * time.Second
+
+// snapshot is the on-disk representation of a Store.
+type snapshot struct {
+	Users    map[string]*UserData
+	Sessions map[string]*github.Session
+	States   map[string]time.Time
+}
+
+// FileStore is a Store persisted to a single file, so sessions and cached
+// stats survive restarts. Changes are written periodically and on Close.
+type FileStore struct {
+	*Store
+	path    string
+	dirty   atomic.Bool
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+var _ Backend = (*FileStore)(nil)
+
+// NewFileStore loads the snapshot at path, if any, and starts persisting
+// changes to it. Loaded user data counts against limits like any other.
+func NewFileStore(path string, limits Limits) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	fs := &FileStore{
+		Store: NewWithLimits(limits),
+		path:  path,
+		stop:  make(chan struct{}),
+	}
+	if err := fs.load(); err != nil {
+		return nil, err
+	}
+
+	fs.stopped.Add(1)
+	go fs.flushLoop()
+	return fs, nil
+}
+
+func (fs *FileStore) load() error {
+	f, err := os.Open(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open cache file: %w", err)
+	}
+	defer f.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		log.Printf("Warning: ignoring unreadable cache file %s: %v", fs.path, err)
+		return nil
+	}
+
+	now := time.Now()
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	// Insert oldest first so the most recently updated users are the last
+	// to be evicted when the snapshot exceeds the limits.
+	keys := make([]string, 0, len(snap.Users))
+	for key, data := range snap.Users {
+		if data != nil && now.Sub(data.UpdatedAt) <= StatsCacheTTL+StaleGracePeriod {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return snap.Users[keys[i]].UpdatedAt.Before(snap.Users[keys[j]].UpdatedAt)
+	})
+	for _, key := range keys {
+		fs.users[key] = snap.Users[key]
+		fs.resize(key, snap.Users[key])
+	}
+	for id, session := range snap.Sessions {
+		if session != nil && now.Before(session.ExpiresAt) {
+			fs.sessions[id] = session
+		}
+	}
+	for state, created := range snap.States {
+		fs.states[state] = created
+	}
+	log.Printf("Loaded cache from %s: %d users, %d sessions", fs.path, len(fs.users), len(fs.sessions))
+	return nil
+}
+
+func (fs *FileStore) flushLoop() {
+	defer fs.stopped.Done()
+	ticker := time.NewTicker(fileFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-fs.stop:
+			return
+		case <-ticker.C:
+			if err := fs.Flush(); err != nil {
+				log.Printf("Warning: failed to persist cache: %v", err)
+			}
+		}
+	}
+}
+
+// Flush writes the snapshot if anything changed since the last write. The
+// file is replaced atomically so a crash never leaves a partial snapshot.
+func (fs *FileStore) Flush() error {
+	if !fs.dirty.Swap(false) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".*.tmp")
+	if err != nil {
+		fs.dirty.Store(true)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	fs.mu.RLock()
+	err = gob.NewEncoder(tmp).Encode(snapshot{
+		Users:    fs.users,
+		Sessions: fs.sessions,
+		States:   fs.states,
+	})
+	fs.mu.RUnlock()
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), fs.path)
+	}
+	if err != nil {
+		fs.dirty.Store(true)
+	}
+	return err
+}
+
+// Close stops the background writer and persists pending changes.
+func (fs *FileStore) Close() error {
+	fs.once.Do(func() {
+		close(fs.stop)
+		fs.stopped.Wait()
+		fs.Store.Close()
+	})
+	return fs.Flush()
+}
+
+func (fs *FileStore) SetStats(username string, stats *github.Stats) {
+	fs.Store.SetStats(username, stats)
+	fs.dirty.Store(true)
+}
+
+func (fs *FileStore) SetCommits(username string, commits []github.Commit) {
+	fs.Store.SetCommits(username, commits)
+	fs.dirty.Store(true)
+}
+
+func (fs *FileStore) CreateState() string {
+	state := fs.Store.CreateState()
+	fs.dirty.Store(true)
+	return state
+}
+
+func (fs *FileStore) ValidateState(state string) bool {
+	ok := fs.Store.ValidateState(state)
+	if ok {
+		fs.dirty.Store(true)
+	}
+	return ok
+}
+
+func (fs *FileStore) CreateSession(username, accessToken, avatarURL string) *github.Session {
+	session := fs.Store.CreateSession(username, accessToken, avatarURL)
+	// Persist sessions right away so a deploy shortly after login keeps them.
+	fs.dirty.Store(true)
+	if err := fs.Flush(); err != nil {
+		log.Printf("Warning: failed to persist session: %v", err)
+	}
+	return session
+}
+
+func (fs *FileStore) DeleteSession(id string) {
+	fs.Store.DeleteSession(id)
+	fs.dirty.Store(true)
+}
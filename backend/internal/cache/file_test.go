@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestFileStore_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	store, err := NewFileStore(path, DefaultLimits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	session := store.CreateSession("testuser", "ghp_token", "avatar")
+	store.SetStats("testuser:public", &github.Stats{Profile: github.Profile{Login: "testuser"}})
+	store.SetCommits("testuser:public", []github.Commit{{SHA: "abc"}})
+	if err := store.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	reopened, err := NewFileStore(path, DefaultLimits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	got := reopened.GetSession(session.ID)
+	if got == nil {
+		t.Fatal("expected session to survive restart")
+	}
+	if got.AccessToken != "ghp_token" {
+		t.Errorf("expected access token to be persisted, got %q", got.AccessToken)
+	}
+	stats := reopened.GetStats("testuser:public")
+	if stats == nil || stats.Profile.Login != "testuser" {
+		t.Error("expected stats to survive restart")
+	}
+	if len(reopened.GetCommits("testuser:public")) != 1 {
+		t.Error("expected commits to survive restart")
+	}
+}
+
+func TestFileStore_DeletedSessionStaysDeleted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	store, _ := NewFileStore(path, DefaultLimits)
+	session := store.CreateSession("testuser", "token", "avatar")
+	store.DeleteSession(session.ID)
+	store.Close()
+
+	reopened, _ := NewFileStore(path, DefaultLimits)
+	defer reopened.Close()
+
+	if reopened.GetSession(session.ID) != nil {
+		t.Error("expected deleted session to stay deleted")
+	}
+}
+
+func TestFileStore_IgnoresCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	if err := os.WriteFile(path, []byte("not a snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileStore(path, DefaultLimits)
+	if err != nil {
+		t.Fatalf("expected corrupt file to be ignored, got %v", err)
+	}
+	defer store.Close()
+
+	if store.GetStats("anything") != nil {
+		t.Error("expected empty store")
+	}
+}
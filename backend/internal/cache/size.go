@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"unsafe"
+
+	"gh-stats/backend/internal/github"
+)
+
+// Approximate sizes of the fixed parts of cached values. Strings are counted
+// separately by length, which dominates for commit histories.
+var (
+	commitSize       = int64(unsafe.Sizeof(github.Commit{}))
+	repositorySize   = int64(unsafe.Sizeof(github.Repository{}))
+	contributionSize = int64(unsafe.Sizeof(github.ContributionDay{}))
+	languageSize     = int64(unsafe.Sizeof(github.LanguageStats{}))
+	statsSize        = int64(unsafe.Sizeof(github.Stats{}))
+	userDataSize     = int64(unsafe.Sizeof(UserData{}))
+)
+
+// sizeOf estimates the heap memory retained by a cache entry. It is not
+// exact, but it scales with what actually grows: commit and repo counts and
+// the text they carry.
+func sizeOf(key string, data *UserData) int64 {
+	size := userDataSize + int64(len(key))
+	for i := range data.Commits {
+		c := &data.Commits[i]
+		size += commitSize + int64(len(c.SHA)+len(c.Message)+len(c.Author)+len(c.Email)+len(c.AuthorLogin)+len(c.URL)+len(c.Repo))
+	}
+	if stats := data.Stats; stats != nil {
+		size += statsSize
+		p := &stats.Profile
+		size += int64(len(p.Login) + len(p.NodeID) + len(p.Name) + len(p.Email) + len(p.AvatarURL) + len(p.Bio) + len(p.Location) + len(p.Company) + len(p.Blog) + len(p.CreatedAt))
+		for i := range stats.Repositories {
+			r := &stats.Repositories[i]
+			size += repositorySize + int64(len(r.Name)+len(r.FullName)+len(r.Description)+len(r.URL)+len(r.Language)+len(r.UpdatedAt)+len(r.Affiliation))
+		}
+		for _, week := range stats.Contributions {
+			for _, day := range week.Days {
+				size += contributionSize + int64(len(day.Date))
+			}
+		}
+		for _, lang := range stats.Languages {
+			size += languageSize + int64(len(lang.Name)+len(lang.Color))
+		}
+		for _, lang := range stats.LanguagesByBytes {
+			size += languageSize + int64(len(lang.Name)+len(lang.Color))
+		}
+	}
+	return size
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+)
+
+// TestSizeOf_CountsEveryStringField fails when a string field is added to a
+// cached type without being counted by sizeOf.
+func TestSizeOf_CountsEveryStringField(t *testing.T) {
+	data := &UserData{
+		Stats: &github.Stats{
+			Repositories:     []github.Repository{{}},
+			Contributions:    []github.ContributionWeek{{Days: []github.ContributionDay{{}}}},
+			Languages:        []github.LanguageStats{{}},
+			LanguagesByBytes: []github.LanguageStats{{}},
+		},
+		Commits: []github.Commit{{}},
+	}
+	targets := map[string]reflect.Value{
+		"Commit":          reflect.ValueOf(&data.Commits[0]).Elem(),
+		"Profile":         reflect.ValueOf(&data.Stats.Profile).Elem(),
+		"Repository":      reflect.ValueOf(&data.Stats.Repositories[0]).Elem(),
+		"ContributionDay": reflect.ValueOf(&data.Stats.Contributions[0].Days[0]).Elem(),
+		"LanguageStats":   reflect.ValueOf(&data.Stats.Languages[0]).Elem(),
+		"Stats":           reflect.ValueOf(data.Stats).Elem(),
+	}
+
+	const n = 100
+	for name, v := range targets {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if field.Kind() != reflect.String {
+				continue
+			}
+			before := sizeOf("key", data)
+			field.SetString(strings.Repeat("x", n))
+			if grew := sizeOf("key", data) - before; grew != n {
+				t.Errorf("%s.%s: expected size to grow by %d, got %d", name, v.Type().Field(i).Name, n, grew)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package card renders user stats as SVG cards that can be embedded as
+// images, e.g. in a GitHub profile README.
+package card
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Card widths are clamped to this range; zero selects a card's default.
+const (
+	MinWidth = 250
+	MaxWidth = 1000
+)
+
+const (
+	padding   = 25
+	titleSize = 18
+	fontStack = `-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif`
+)
+
+// Options controls how a card is drawn.
+type Options struct {
+	Theme Theme
+	// Width in pixels; heights follow from the content.
+	Width int
+	// Hide holds lower-case names of fields to leave out. "title" applies
+	// to every card; on the languages card language names can be hidden.
+	Hide       map[string]bool
+	HideBorder bool
+	// MaxItems limits the languages listed; zero keeps the default.
+	MaxItems int
+}
+
+func (o Options) hidden(field string) bool {
+	return o.Hide[strings.ToLower(field)]
+}
+
+func (o Options) width(def int) int {
+	if o.Width == 0 {
+		return def
+	}
+	return min(max(o.Width, MinWidth), MaxWidth)
+}
+
+// svg accumulates the elements of a card inside its frame.
+type svg struct {
+	b      strings.Builder
+	opts   Options
+	width  int
+	height int
+	// top is where content starts below the optional title.
+	top int
+}
+
+func newSVG(opts Options, width int, title string) *svg {
+	s := &svg{opts: opts, width: width, top: padding}
+	if title != "" && !opts.hidden("title") {
+		fmt.Fprintf(&s.b, `<text x="%d" y="%d" class="title">%s</text>`, padding, padding+titleSize-4, html.EscapeString(title))
+		s.top = padding + titleSize + 20
+	}
+	return s
+}
+
+func (s *svg) printf(format string, args ...any) {
+	fmt.Fprintf(&s.b, format, args...)
+}
+
+// bytes wraps the content in the card frame with the given content height.
+func (s *svg) bytes(contentHeight int) []byte {
+	s.height = s.top + contentHeight + padding
+	t := s.opts.Theme
+	stroke := t.Border
+	if s.opts.HideBorder {
+		stroke = "none"
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img">`,
+		s.width, s.height, s.width, s.height)
+	fmt.Fprintf(&out, `<style>text{font-family:%s;fill:%s;font-size:14px}.title{font-size:%dpx;font-weight:600;fill:%s}.muted{fill:%s;font-size:12px}.big{font-size:28px;font-weight:700}.accent{fill:%s}</style>`,
+		fontStack, t.Text, titleSize, t.Title, t.Muted, t.Accent)
+	fmt.Fprintf(&out, `<rect x="0.5" y="0.5" rx="4.5" width="%d" height="%d" fill="%s" stroke="%s"/>`,
+		s.width-1, s.height-1, t.Background, stroke)
+	out.WriteString(s.b.String())
+	out.WriteString(`</svg>`)
+	return []byte(out.String())
+}
+
+// Error renders a card showing message, so embedded images explain a failure
+// instead of appearing broken.
+func Error(message string, opts Options) []byte {
+	s := newSVG(opts, opts.width(495), "Something went wrong")
+	s.printf(`<text x="%d" y="%d" class="muted">%s</text>`, padding, s.top+10, html.EscapeString(message))
+	return s.bytes(15)
+}
+
+// formatInt formats n with thousands separators.
+func formatInt(n int) string {
+	digits := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatInt(-n)
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// formatPercent formats p with at most one decimal.
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,102 @@
+package card
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+)
+
+func testOptions() Options {
+	theme, _ := LookupTheme(DefaultTheme)
+	return Options{Theme: theme, Hide: map[string]bool{}}
+}
+
+// parse checks that svg is well-formed XML.
+func parse(t *testing.T, svg []byte) string {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(string(svg)))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+	}
+	return string(svg)
+}
+
+func TestStreak_HidesFields(t *testing.T) {
+	opts := testOptions()
+	opts.Hide["total"] = true
+	svg := parse(t, Streak(github.StreakStats{CurrentStreak: 1, LongestStreak: 1234, TotalContributions: 99}, opts))
+
+	if strings.Contains(svg, "Total Contributions") {
+		t.Error("expected the total to be hidden")
+	}
+	if !strings.Contains(svg, ">1 day<") || !strings.Contains(svg, ">1,234 days<") {
+		t.Errorf("expected formatted streaks, got %s", svg)
+	}
+}
+
+func TestLanguages_RenormalizesShownLanguages(t *testing.T) {
+	opts := testOptions()
+	opts.Hide["html"] = true
+	languages := []github.LanguageStats{
+		{Name: "Go", Percentage: 30, Color: "#00ADD8"},
+		{Name: "HTML", Percentage: 40, Color: "#e34c26"},
+		{Name: "C<++>", Percentage: 30, Color: "#f34b7d"},
+	}
+	svg := parse(t, Languages(languages, opts))
+
+	if strings.Contains(svg, "HTML") {
+		t.Error("expected HTML to be hidden")
+	}
+	if strings.Count(svg, ">50%<") != 2 {
+		t.Errorf("expected the remaining languages at 50%% each, got %s", svg)
+	}
+	if !strings.Contains(svg, "C&lt;++&gt;") {
+		t.Error("expected language names to be escaped")
+	}
+}
+
+func TestContributions_UsesThemeLevels(t *testing.T) {
+	opts := testOptions()
+	opts.Theme, _ = LookupTheme("dark")
+	opts.Width = 5000
+	weeks := []github.ContributionWeek{{Days: []github.ContributionDay{{Date: "2025-01-01", Count: 9, Level: 4}}}}
+	svg := parse(t, Contributions(weeks, 1500, opts))
+
+	if !strings.Contains(svg, `width="1000"`) {
+		t.Error("expected the width to be clamped to MaxWidth")
+	}
+	if !strings.Contains(svg, opts.Theme.Heat[4]) || !strings.Contains(svg, "1,500 contributions") {
+		t.Errorf("expected the level 4 color and total, got %s", svg)
+	}
+}
+
+func TestFun_HideBorderAndTitle(t *testing.T) {
+	opts := testOptions()
+	opts.HideBorder = true
+	opts.Hide["title"] = true
+	svg := parse(t, Fun(github.FunStats{MostProductiveHour: 9, MostProductiveDay: "Tuesday", MostActiveRepo: "a&b"}, opts))
+
+	if strings.Contains(svg, "Coding Habits") || !strings.Contains(svg, `stroke="none"`) {
+		t.Errorf("expected no title and no border, got %s", svg)
+	}
+	if !strings.Contains(svg, ">09:00<") || !strings.Contains(svg, "a&amp;b") {
+		t.Errorf("expected the hour and escaped repo, got %s", svg)
+	}
+}
+
+func TestFormatInt(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4500: "-4,500"} {
+		if got := formatInt(n); got != want {
+			t.Errorf("formatInt(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package card
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+
+	"gh-stats/backend/internal/github"
+)
+
+// defaultLanguages is the number of languages listed unless MaxItems is set.
+const defaultLanguages = 6
+
+// Streak renders total contributions and the current and longest streaks.
+// Fields: total, current, longest.
+func Streak(streak github.StreakStats, opts Options) []byte {
+	s := newSVG(opts, opts.width(495), "Contribution Streak")
+
+	type column struct {
+		field, label, value string
+		accent              bool
+	}
+	var columns []column
+	for _, c := range []column{
+		{"total", "Total Contributions", formatInt(streak.TotalContributions), false},
+		{"current", "Current Streak", days(streak.CurrentStreak), true},
+		{"longest", "Longest Streak", days(streak.LongestStreak), false},
+	} {
+		if !opts.hidden(c.field) {
+			columns = append(columns, c)
+		}
+	}
+
+	if len(columns) > 0 {
+		columnWidth := float64(s.width-2*padding) / float64(len(columns))
+		for i, c := range columns {
+			x := float64(padding) + columnWidth*(float64(i)+0.5)
+			class := "big"
+			if c.accent {
+				class += " accent"
+			}
+			s.printf(`<text x="%.1f" y="%d" text-anchor="middle" class="%s">%s</text>`, x, s.top+28, class, c.value)
+			s.printf(`<text x="%.1f" y="%d" text-anchor="middle" class="muted">%s</text>`, x, s.top+52, c.label)
+		}
+	}
+	return s.bytes(60)
+}
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return formatInt(n) + " days"
+}
+
+// Languages renders a bar of the languages' shares with a two-column
+// legend. Hidden languages are left out and the rest renormalized.
+func Languages(languages []github.LanguageStats, opts Options) []byte {
+	s := newSVG(opts, opts.width(350), "Most Used Languages")
+
+	limit := opts.MaxItems
+	if limit <= 0 {
+		limit = defaultLanguages
+	}
+	var shown []github.LanguageStats
+	var sum float64
+	for _, lang := range languages {
+		if opts.hidden(lang.Name) || len(shown) == limit {
+			continue
+		}
+		shown = append(shown, lang)
+		sum += lang.Percentage
+	}
+	if len(shown) == 0 || sum == 0 {
+		s.printf(`<text x="%d" y="%d" class="muted">No languages to show</text>`, padding, s.top+10)
+		return s.bytes(15)
+	}
+
+	barWidth := float64(s.width - 2*padding)
+	s.printf(`<clipPath id="bar"><rect x="%d" y="%d" width="%.1f" height="8" rx="4"/></clipPath><g clip-path="url(#bar)">`,
+		padding, s.top, barWidth)
+	x := float64(padding)
+	for _, lang := range shown {
+		w := barWidth * lang.Percentage / sum
+		s.printf(`<rect x="%.1f" y="%d" width="%.1f" height="8" fill="%s"/>`, x, s.top, w, html.EscapeString(lang.Color))
+		x += w
+	}
+	s.b.WriteString(`</g>`)
+
+	rows := (len(shown) + 1) / 2
+	columnWidth := (s.width - 2*padding) / 2
+	for i, lang := range shown {
+		cx := padding + (i/rows)*columnWidth
+		cy := s.top + 30 + (i%rows)*22
+		share := math.Round(lang.Percentage/sum*1000) / 10
+		s.printf(`<circle cx="%d" cy="%d" r="5" fill="%s"/>`, cx+5, cy-4, html.EscapeString(lang.Color))
+		s.printf(`<text x="%d" y="%d">%s <tspan class="muted">%s</tspan></text>`, cx+16, cy, html.EscapeString(lang.Name), formatPercent(share))
+	}
+	return s.bytes(30 + (rows-1)*22)
+}
+
+// Contributions renders a contribution heatmap from the calendar's levels.
+// Fields: total, legend.
+func Contributions(weeks []github.ContributionWeek, total int, opts Options) []byte {
+	title := "Contributions"
+	if !opts.hidden("total") {
+		title = formatInt(total) + " contributions in the last year"
+	}
+	s := newSVG(opts, opts.width(720), title)
+
+	cell := 2
+	if len(weeks) > 0 {
+		cell = max(cell, (s.width-2*padding)/len(weeks))
+	}
+	for i, week := range weeks {
+		for j, day := range week.Days {
+			level := min(max(day.Level, 0), 4)
+			s.printf(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %s</title></rect>`,
+				padding+i*cell, s.top+j*cell, cell-2, cell-2, opts.Theme.Heat[level],
+				html.EscapeString(day.Date), strconv.Itoa(day.Count))
+		}
+	}
+	height := 7 * cell
+
+	if !opts.hidden("legend") {
+		y := s.top + height + 8
+		x := s.width - padding - 5*cell - 70
+		s.printf(`<text x="%d" y="%d" class="muted">Less</text>`, x, y+cell-4)
+		for level, color := range opts.Theme.Heat {
+			s.printf(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`, x+32+level*cell, y, cell-2, cell-2, color)
+		}
+		s.printf(`<text x="%d" y="%d" class="muted">More</text>`, x+36+5*cell, y+cell-4)
+		height += 8 + cell
+	}
+	return s.bytes(height)
+}
+
+// Fun renders commit habits. Fields: hour, day, night, early, weekend,
+// streak, repo.
+func Fun(fun github.FunStats, opts Options) []byte {
+	s := newSVG(opts, opts.width(400), "Coding Habits")
+
+	rows := [][3]string{
+		{"hour", "Most productive hour", fmt.Sprintf("%02d:00", fun.MostProductiveHour)},
+		{"day", "Most productive day", fun.MostProductiveDay},
+		{"night", "Night owl", formatPercent(fun.NightOwlPercent)},
+		{"early", "Early bird", formatPercent(fun.EarlyBirdPercent)},
+		{"weekend", "Weekend warrior", formatPercent(fun.WeekendWarriorPercent)},
+		{"streak", "Longest coding streak", days(fun.LongestCodingStreak)},
+		{"repo", "Most active repository", fun.MostActiveRepo},
+	}
+
+	y := s.top + 10
+	shown := 0
+	for _, row := range rows {
+		if opts.hidden(row[0]) || row[2] == "" {
+			continue
+		}
+		s.printf(`<text x="%d" y="%d" class="muted">%s</text>`, padding, y, row[1])
+		s.printf(`<text x="%d" y="%d" text-anchor="end">%s</text>`, s.width-padding, y, html.EscapeString(row[2]))
+		y += 25
+		shown++
+	}
+	if shown == 0 {
+		return s.bytes(0)
+	}
+	return s.bytes(10 + (shown-1)*25)
+}
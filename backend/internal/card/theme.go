@@ -0,0 +1,53 @@
+package card
+
+import "sort"
+
+// Theme holds the colors of a card. Heat colors the five contribution
+// levels of the heatmap, from none to most.
+type Theme struct {
+	Background string
+	Border     string
+	Title      string
+	Text       string
+	Muted      string
+	Accent     string
+	Heat       [5]string
+}
+
+// DefaultTheme is used when no theme is requested.
+const DefaultTheme = "light"
+
+var themes = map[string]Theme{
+	"light": {
+		Background: "#ffffff", Border: "#e4e2e2", Title: "#2f80ed", Text: "#434d58", Muted: "#6e7781", Accent: "#fb8c00",
+		Heat: [5]string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"},
+	},
+	"dark": {
+		Background: "#0d1117", Border: "#30363d", Title: "#58a6ff", Text: "#c9d1d9", Muted: "#8b949e", Accent: "#f78166",
+		Heat: [5]string{"#161b22", "#0e4429", "#006d32", "#26a641", "#39d353"},
+	},
+	"dracula": {
+		Background: "#282a36", Border: "#44475a", Title: "#ff79c6", Text: "#f8f8f2", Muted: "#6272a4", Accent: "#50fa7b",
+		Heat: [5]string{"#343746", "#5a4a78", "#7d5fa8", "#a879d6", "#ff79c6"},
+	},
+	"solarized": {
+		Background: "#fdf6e3", Border: "#eee8d5", Title: "#268bd2", Text: "#586e75", Muted: "#93a1a1", Accent: "#cb4b16",
+		Heat: [5]string{"#eee8d5", "#b5d99c", "#859900", "#5f7300", "#3d4a00"},
+	},
+}
+
+// LookupTheme returns the named theme.
+func LookupTheme(name string) (Theme, bool) {
+	theme, ok := themes[name]
+	return theme, ok
+}
+
+// ThemeNames lists the available themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,10 +1,11 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -53,44 +54,117 @@ func (c *Client) WithToken(token string) *Client {
 	}
 }
 
-func (c *Client) request(endpoint string, result any) error {
-	req, err := http.NewRequest("GET", apiURL+endpoint, nil)
-	if err != nil {
-		return err
-	}
-
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+// do sends a request built by newReq, tracking the rate limit budget of the
+// resource and retrying when GitHub asks us to back off.
+func (c *Client) do(ctx context.Context, resource string, newReq func() (*http.Request, error)) (*http.Response, error) {
+	identity := tokenIdentity(c.token)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForBudget(ctx, resource); err != nil {
+			return nil, err
+		}
+
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		rateLimits.update(identity, resource, resp.Header)
+
+		if attempt < maxRetries {
+			if wait, ok := retryDelay(resp, attempt); ok {
+				resp.Body.Close()
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		return resp, nil
 	}
-	req.Header.Set("Accept", "application/vnd.github+json")
+}
 
-	resp, err := c.http.Do(req)
+func (c *Client) request(ctx context.Context, endpoint string, result any) error {
+	resource := ResourceCore
+	if strings.HasPrefix(endpoint, "/search/") {
+		resource = ResourceSearch
+	}
+
+	url := apiURL + endpoint
+	cacheKey := responseCacheKey(c.token, url)
+	cached := responses.get(cacheKey)
+
+	resp, err := c.do(ctx, resource, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if cached != nil {
+			if cached.etag != "" {
+				req.Header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+		return req, nil
+	})
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("not found")
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return json.Unmarshal(cached.body, result)
 	}
 
-	if resp.StatusCode == http.StatusAccepted {
-		return fmt.Errorf("accepted: stats being computed")
+	if resp.StatusCode != http.StatusOK {
+		return errorFromResponse(resp, resource)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GitHub API error %d: %s", resp.StatusCode, string(body))
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag != "" || lastModified != "" {
+		responses.set(&cachedResponse{
+			key:          cacheKey,
+			etag:         etag,
+			lastModified: lastModified,
+			body:         body,
+		})
 	}
 
-	return json.NewDecoder(resp.Body).Decode(result)
+	return json.Unmarshal(body, result)
 }
 
-func (c *Client) graphql(query string, result any) error {
-	return c.graphqlWithVars(query, nil, result)
+func (c *Client) graphql(ctx context.Context, query string, result any) error {
+	return c.graphqlWithVars(ctx, query, nil, result)
 }
 
-func (c *Client) graphqlWithVars(query string, variables map[string]any, result any) error {
+func (c *Client) graphqlWithVars(ctx context.Context, query string, variables map[string]any, result any) error {
+	errs, err := c.graphqlPartial(ctx, query, variables, result)
+	if len(errs) > 0 {
+		return &errs[0]
+	}
+	return err
+}
+
+// graphqlPartial runs a query whose fields can fail on their own, such as
+// one aliased field per user. It decodes whatever data came back into
+// result and returns the errors of the fields that failed.
+func (c *Client) graphqlPartial(ctx context.Context, query string, variables map[string]any, result any) ([]GraphQLError, error) {
 	payload := map[string]any{"query": query}
 	if variables != nil {
 		payload["variables"] = variables
@@ -98,46 +172,36 @@ func (c *Client) graphqlWithVars(query string, variables map[string]any, result
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", graphqlURL, nil)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	req.Body = io.NopCloser(jsonReader(body))
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.http.Do(req)
+	resp, err := c.do(ctx, ResourceGraphQL, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", graphqlURL, jsonReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GitHub GraphQL error %d: %s", resp.StatusCode, string(respBody))
+		return nil, errorFromResponse(resp, ResourceGraphQL)
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var graphqlResponse struct {
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-	if err := json.Unmarshal(respBody, &graphqlResponse); err == nil && len(graphqlResponse.Errors) > 0 {
-		return fmt.Errorf("GraphQL error: %s", graphqlResponse.Errors[0].Message)
+		Errors []GraphQLError `json:"errors"`
 	}
-
-	return json.Unmarshal(respBody, result)
+	json.Unmarshal(respBody, &graphqlResponse)
+	return graphqlResponse.Errors, json.Unmarshal(respBody, result)
 }
 
 type jsonReaderType []byte
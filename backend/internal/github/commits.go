@@ -0,0 +1,133 @@
+package github
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CommitFilter selects the commits attributed to a user. The zero value
+// matches every commit.
+type CommitFilter struct {
+	// Login is the GitHub login commits must be linked to.
+	Login string
+	// UserID is the GraphQL node ID of the user, used by the GraphQL
+	// history crawler to filter by author.
+	UserID string
+	// Emails are additional author emails of the user, for commits made
+	// with addresses that are not linked to the account.
+	Emails []string
+	// IncludeCoAuthored also counts commits naming the user in a
+	// Co-authored-by trailer.
+	IncludeCoAuthored bool
+	// Since, when set, limits crawls to commits authored at or after it.
+	// It bounds how much history is fetched rather than who matches.
+	Since time.Time
+}
+
+// IsZero reports whether the filter matches every commit.
+func (f CommitFilter) IsZero() bool {
+	return f.Login == "" && len(f.Emails) == 0
+}
+
+// requestsPerRepo is the minimum number of commit listings needed per
+// repository: co-authored commits cannot be filtered server-side, so they
+// need one unfiltered listing; otherwise each identity is its own author=
+// query.
+func (f CommitFilter) requestsPerRepo() int {
+	if f.IsZero() || f.IncludeCoAuthored {
+		return 1
+	}
+	return len(f.authors())
+}
+
+// sinceParam is the REST since= parameter for the filter's window, or "".
+func (f CommitFilter) sinceParam() string {
+	if f.Since.IsZero() {
+		return ""
+	}
+	return "&since=" + url.QueryEscape(f.Since.UTC().Format(time.RFC3339))
+}
+
+// authors returns the values passed as the REST author= parameter, which
+// accepts a login or an email address.
+func (f CommitFilter) authors() []string {
+	var authors []string
+	if f.Login != "" {
+		authors = append(authors, f.Login)
+	}
+	for _, email := range f.Emails {
+		if email != "" && !f.isNoreplyEmail(email) {
+			authors = append(authors, email)
+		}
+	}
+	return authors
+}
+
+// Matches reports whether c was written by the user, as author or, when
+// IncludeCoAuthored is set, as co-author.
+func (f CommitFilter) Matches(c Commit) bool {
+	if f.IsZero() {
+		return true
+	}
+	if f.Login != "" && strings.EqualFold(c.AuthorLogin, f.Login) {
+		return true
+	}
+	if f.ownsEmail(c.Email) {
+		return true
+	}
+	if f.IncludeCoAuthored {
+		for _, co := range parseCoAuthors(c.Message) {
+			if f.ownsEmail(co.Email) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f CommitFilter) ownsEmail(email string) bool {
+	if email == "" {
+		return false
+	}
+	for _, own := range f.Emails {
+		if strings.EqualFold(own, email) {
+			return true
+		}
+	}
+	return f.isNoreplyEmail(email)
+}
+
+// isNoreplyEmail reports whether email is the user's GitHub noreply address,
+// either "login@users.noreply.github.com" or "id+login@users.noreply.github.com".
+func (f CommitFilter) isNoreplyEmail(email string) bool {
+	if f.Login == "" {
+		return false
+	}
+	local, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok || domain != "users.noreply.github.com" {
+		return false
+	}
+	if _, name, found := strings.Cut(local, "+"); found {
+		local = name
+	}
+	return local == strings.ToLower(f.Login)
+}
+
+// CoAuthor is a person credited in a Co-authored-by commit trailer.
+type CoAuthor struct {
+	Name  string
+	Email string
+}
+
+var coAuthorTrailer = regexp.MustCompile(`(?mi)^co-authored-by:[ \t]*(.*?)[ \t]*<([^>\s]+)>[ \t]*$`)
+
+// parseCoAuthors extracts the Co-authored-by trailers from a commit message.
+func parseCoAuthors(message string) []CoAuthor {
+	var coAuthors []CoAuthor
+	for _, m := range coAuthorTrailer.FindAllStringSubmatch(message, -1) {
+		coAuthors = append(coAuthors, CoAuthor{Name: m[1], Email: m[2]})
+	}
+	return coAuthors
+}
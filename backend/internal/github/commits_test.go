@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCoAuthors(t *testing.T) {
+	message := "Fix parser\n\nCo-authored-by: Jane Doe <jane@example.com>\nco-authored-by: bot <123+bot@users.noreply.github.com>\n"
+
+	got := parseCoAuthors(message)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 co-authors, got %d", len(got))
+	}
+	if got[0].Name != "Jane Doe" || got[0].Email != "jane@example.com" {
+		t.Errorf("unexpected first co-author: %+v", got[0])
+	}
+	if got[1].Email != "123+bot@users.noreply.github.com" {
+		t.Errorf("unexpected second co-author email: %s", got[1].Email)
+	}
+}
+
+func TestCommitFilter_Matches(t *testing.T) {
+	filter := CommitFilter{Login: "octocat", Emails: []string{"octo@example.com"}}
+	coFilter := filter
+	coFilter.IncludeCoAuthored = true
+
+	coAuthored := Commit{
+		AuthorLogin: "teammate",
+		Email:       "team@example.com",
+		Message:     "Pair on it\n\nCo-authored-by: Octo <octo@example.com>",
+	}
+
+	tests := []struct {
+		name   string
+		filter CommitFilter
+		commit Commit
+		want   bool
+	}{
+		{"zero filter matches all", CommitFilter{}, Commit{AuthorLogin: "someone"}, true},
+		{"linked login", filter, Commit{AuthorLogin: "OctoCat"}, true},
+		{"known email", filter, Commit{Email: "OCTO@example.com"}, true},
+		{"noreply email", filter, Commit{Email: "583231+octocat@users.noreply.github.com"}, true},
+		{"other noreply email", filter, Commit{Email: "1+octocatfan@users.noreply.github.com"}, false},
+		{"collaborator", filter, Commit{AuthorLogin: "teammate", Email: "team@example.com"}, false},
+		{"co-authored excluded by default", filter, coAuthored, false},
+		{"co-authored included", coFilter, coAuthored, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.commit); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GetAuthoredCommits_FiltersByAuthor(t *testing.T) {
+	var authors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		author := r.URL.Query().Get("author")
+		authors = append(authors, author)
+		commits := []map[string]any{}
+		switch author {
+		case "octocat":
+			commits = append(commits,
+				map[string]any{"sha": "a1", "author": map[string]any{"login": "octocat"}},
+				map[string]any{"sha": "shared", "author": map[string]any{"login": "octocat"}},
+			)
+		case "old@example.com":
+			commits = append(commits, map[string]any{"sha": "shared"}, map[string]any{"sha": "e1"})
+		}
+		json.NewEncoder(w).Encode(commits)
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	filter := CommitFilter{Login: "octocat", Emails: []string{"old@example.com"}}
+	commits, err := NewClient("ghp_authored").GetAuthoredCommits(context.Background(), "octocat", "repo", filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(authors) != 2 {
+		t.Errorf("expected one listing per identity, got %v", authors)
+	}
+	if len(commits) != 3 {
+		t.Errorf("expected 3 distinct commits, got %d", len(commits))
+	}
+	if commits[0].AuthorLogin != "octocat" {
+		t.Errorf("expected author login to be parsed, got %q", commits[0].AuthorLogin)
+	}
+}
+
+func TestClient_GetAuthoredCommits_IncludesCoAuthored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("author") != "" {
+			t.Errorf("expected unfiltered listing for co-authored commits")
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"sha": "own", "author": map[string]any{"login": "octocat"}},
+			{"sha": "bot", "author": map[string]any{"login": "dependabot[bot]"}},
+			{
+				"sha":    "pair",
+				"author": map[string]any{"login": "teammate"},
+				"commit": map[string]any{"message": "Pair\n\nCo-authored-by: Octo <1+octocat@users.noreply.github.com>"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	filter := CommitFilter{Login: "octocat", IncludeCoAuthored: true}
+	commits, err := NewClient("ghp_coauthored").GetAuthoredCommits(context.Background(), "octocat", "repo", filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(commits) != 2 {
+		t.Fatalf("expected own and co-authored commits, got %d", len(commits))
+	}
+	if commits[0].CoAuthored || !commits[1].CoAuthored {
+		t.Errorf("expected only the paired commit to be marked co-authored, got %+v", commits)
+	}
+}
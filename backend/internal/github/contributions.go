@@ -0,0 +1,238 @@
+package github
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCachedYears bounds the past-year calendars kept in memory. A year
+	// is roughly 20KB.
+	maxCachedYears = 2000
+	// yearWorkers bounds the calendar queries in flight for one user.
+	yearWorkers = 4
+)
+
+type yearCalendar struct {
+	key   string
+	weeks []ContributionWeek
+	total int
+}
+
+// yearCache is an LRU of contribution calendars of past years, which never
+// change once the year is over.
+type yearCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	maxEntries int
+}
+
+var pastYears = newYearCache(maxCachedYears)
+
+func newYearCache(maxEntries int) *yearCache {
+	return &yearCache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// yearCacheKey keeps calendars of different tokens apart, since a user's own
+// token also sees their private contributions.
+func yearCacheKey(token, username string, year int) string {
+	return tokenIdentity(token) + " " + strings.ToLower(username) + " " + strconv.Itoa(year)
+}
+
+func (c *yearCache) get(key string) *yearCalendar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*yearCalendar)
+}
+
+func (c *yearCache) set(entry *yearCalendar) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.maxEntries {
+		evicted := c.order.Remove(c.order.Back()).(*yearCalendar)
+		delete(c.entries, evicted.key)
+	}
+}
+
+// GetContributionYears returns the years a user has contributions in, most
+// recent first.
+func (c *Client) GetContributionYears(ctx context.Context, username string) ([]int, error) {
+	query := `query($login: String!) {
+		user(login: $login) {
+			contributionsCollection {
+				contributionYears
+			}
+		}
+	}`
+
+	var result struct {
+		Data struct {
+			User *struct {
+				ContributionsCollection struct {
+					ContributionYears []int `json:"contributionYears"`
+				} `json:"contributionsCollection"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+
+	if err := c.graphqlWithVars(ctx, query, map[string]any{"login": username}, &result); err != nil {
+		return nil, err
+	}
+	if result.Data.User == nil {
+		return nil, fmt.Errorf("user %s: %w", username, ErrNotFound)
+	}
+	return result.Data.User.ContributionsCollection.ContributionYears, nil
+}
+
+// GetLifetimeContributions fetches the calendar of every contribution year
+// and computes streaks across all of them, so streaks spanning New Year or
+// set in earlier years are counted. Past years are cached.
+func (c *Client) GetLifetimeContributions(ctx context.Context, username string) (*LifetimeContributions, error) {
+	return c.lifetimeContributions(ctx, username, nil)
+}
+
+// lifetimeContributions is GetLifetimeContributions reusing recent, the
+// calendar of the last year if already fetched, for the current year.
+func (c *Client) lifetimeContributions(ctx context.Context, username string, recent []ContributionWeek) (*LifetimeContributions, error) {
+	years, err := c.GetContributionYears(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if len(years) == 0 {
+		return &LifetimeContributions{
+			Contributions: []ContributionWeek{},
+			Years:         []YearlyContributions{},
+		}, nil
+	}
+
+	calendars := make([]*yearCalendar, len(years))
+	errs := make([]error, len(years))
+	currentYear := time.Now().Year()
+
+	sem := make(chan struct{}, yearWorkers)
+	var wg sync.WaitGroup
+	for i, year := range years {
+		key := yearCacheKey(c.token, username, year)
+		if year == currentYear && recent != nil {
+			calendars[i] = calendarOfYear(recent, year)
+			continue
+		}
+		if year < currentYear {
+			if cached := pastYears.get(key); cached != nil {
+				calendars[i] = cached
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			weeks, total, err := c.GetContributionsForYear(ctx, username, year)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			calendars[i] = &yearCalendar{key: key, weeks: weeks, total: total}
+			if year < currentYear {
+				pastYears.set(calendars[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stitchYears(years, calendars), nil
+}
+
+// calendarOfYear keeps the days of year from a calendar that may span two
+// years, such as the last-year calendar of a profile.
+func calendarOfYear(weeks []ContributionWeek, year int) *yearCalendar {
+	prefix := strconv.Itoa(year) + "-"
+	calendar := &yearCalendar{}
+	for _, week := range weeks {
+		var days []ContributionDay
+		for _, day := range week.Days {
+			if strings.HasPrefix(day.Date, prefix) {
+				days = append(days, day)
+				calendar.total += day.Count
+			}
+		}
+		if len(days) > 0 {
+			calendar.weeks = append(calendar.weeks, ContributionWeek{Days: days})
+		}
+	}
+	return calendar
+}
+
+// stitchYears joins yearly calendars in chronological order. Days after
+// today are dropped so they do not break the current streak.
+func stitchYears(years []int, calendars []*yearCalendar) *LifetimeContributions {
+	order := make([]int, len(years))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return years[order[a]] < years[order[b]] })
+
+	today := time.Now().UTC().Format("2006-01-02")
+	lifetime := &LifetimeContributions{
+		Contributions: []ContributionWeek{},
+		Years:         make([]YearlyContributions, 0, len(years)),
+	}
+	total := 0
+
+	for _, i := range order {
+		for _, week := range calendars[i].weeks {
+			days := make([]ContributionDay, 0, len(week.Days))
+			for _, day := range week.Days {
+				if day.Date <= today {
+					days = append(days, day)
+				}
+			}
+			if len(days) > 0 {
+				lifetime.Contributions = append(lifetime.Contributions, ContributionWeek{Days: days})
+			}
+		}
+
+		yearly := YearlyContributions{Year: years[i], Total: calendars[i].total}
+		lifetime.Years = append(lifetime.Years, yearly)
+		if yearly.Total > lifetime.BestYear.Total {
+			lifetime.BestYear = yearly
+		}
+		total += yearly.Total
+	}
+
+	lifetime.Streak = calculateStreak(lifetime.Contributions, total)
+	return lifetime
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStitchYears_StreakSpansNewYear(t *testing.T) {
+	years := []int{2024, 2023}
+	calendars := []*yearCalendar{
+		{weeks: []ContributionWeek{{Days: []ContributionDay{
+			{Date: "2024-01-01", Count: 1},
+			{Date: "2024-01-02", Count: 1},
+			{Date: "2024-01-03", Count: 0},
+		}}}, total: 2},
+		{weeks: []ContributionWeek{{Days: []ContributionDay{
+			{Date: "2023-12-29", Count: 0},
+			{Date: "2023-12-30", Count: 2},
+			{Date: "2023-12-31", Count: 3},
+		}}}, total: 5},
+	}
+
+	lifetime := stitchYears(years, calendars)
+
+	if lifetime.Streak.LongestStreak != 4 {
+		t.Errorf("expected longest streak 4, got %d", lifetime.Streak.LongestStreak)
+	}
+	if lifetime.Streak.TotalContributions != 7 {
+		t.Errorf("expected total 7, got %d", lifetime.Streak.TotalContributions)
+	}
+	if lifetime.BestYear.Year != 2023 || lifetime.BestYear.Total != 5 {
+		t.Errorf("expected best year 2023 with 5, got %+v", lifetime.BestYear)
+	}
+	if lifetime.Years[0].Year != 2023 {
+		t.Errorf("expected years in chronological order, got %+v", lifetime.Years)
+	}
+}
+
+func TestStitchYears_DropsFutureDays(t *testing.T) {
+	today := time.Now()
+	calendars := []*yearCalendar{{weeks: []ContributionWeek{{Days: []ContributionDay{
+		{Date: today.AddDate(0, 0, -1).Format("2006-01-02"), Count: 1},
+		{Date: today.Format("2006-01-02"), Count: 1},
+		{Date: today.AddDate(0, 0, 1).Format("2006-01-02"), Count: 0},
+	}}}, total: 2}}
+
+	lifetime := stitchYears([]int{today.Year()}, calendars)
+
+	if lifetime.Streak.CurrentStreak != 2 {
+		t.Errorf("expected current streak 2, got %d", lifetime.Streak.CurrentStreak)
+	}
+}
+
+func TestYearCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newYearCache(2)
+	cache.set(&yearCalendar{key: "a"})
+	cache.set(&yearCalendar{key: "b"})
+	cache.get("a")
+	cache.set(&yearCalendar{key: "c"})
+
+	if cache.get("b") != nil {
+		t.Error("expected least recently used year to be evicted")
+	}
+	if cache.get("a") == nil || cache.get("c") == nil {
+		t.Error("expected recently used years to be kept")
+	}
+}
+
+func TestClient_GetLifetimeContributions_CachesPastYears(t *testing.T) {
+	currentYear := time.Now().Year()
+	pastYear := currentYear - 1
+	var calendarQueries atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if strings.Contains(body.Query, "contributionYears") {
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{
+				"contributionsCollection": map[string]any{"contributionYears": []int{currentYear, pastYear}},
+			}}})
+			return
+		}
+
+		calendarQueries.Add(1)
+		year := pastYear
+		if strings.Contains(body.Query, strconv.Itoa(currentYear)+"-01-01") {
+			year = currentYear
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{
+			"contributionsCollection": map[string]any{"contributionCalendar": map[string]any{
+				"totalContributions": 1,
+				"weeks": []map[string]any{{"contributionDays": []map[string]any{
+					{"date": strconv.Itoa(year) + "-03-01", "contributionCount": 1, "contributionLevel": "FIRST_QUARTILE"},
+				}}},
+			}},
+		}}})
+	}))
+	defer server.Close()
+
+	original := SetGraphQLURL(server.URL)
+	defer SetGraphQLURL(original)
+
+	client := NewClient("ghp_lifetime")
+	for i := 0; i < 2; i++ {
+		lifetime, err := client.GetLifetimeContributions(context.Background(), "octocat")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(lifetime.Years) != 2 || lifetime.Streak.TotalContributions != 2 {
+			t.Errorf("expected 2 years with 2 contributions, got %+v", lifetime.Years)
+		}
+	}
+
+	if calendarQueries.Load() != 3 {
+		t.Errorf("expected past year to be fetched once, got %d calendar queries", calendarQueries.Load())
+	}
+}
+
+func TestClient_LifetimeContributions_ReusesRecentCalendar(t *testing.T) {
+	currentYear := time.Now().Year()
+	pastYear := currentYear - 1
+	var currentYearQueries atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if strings.Contains(body.Query, "contributionYears") {
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{
+				"contributionsCollection": map[string]any{"contributionYears": []int{currentYear, pastYear}},
+			}}})
+			return
+		}
+		if strings.Contains(body.Query, strconv.Itoa(currentYear)+"-01-01") {
+			currentYearQueries.Add(1)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{
+			"contributionsCollection": map[string]any{"contributionCalendar": map[string]any{
+				"totalContributions": 4,
+				"weeks": []map[string]any{{"contributionDays": []map[string]any{
+					{"date": strconv.Itoa(pastYear) + "-12-31", "contributionCount": 4, "contributionLevel": "FIRST_QUARTILE"},
+				}}},
+			}},
+		}}})
+	}))
+	defer server.Close()
+	defer SetGraphQLURL(SetGraphQLURL(server.URL))
+
+	recent := []ContributionWeek{{Days: []ContributionDay{
+		{Date: strconv.Itoa(pastYear) + "-12-31", Count: 4},
+		{Date: strconv.Itoa(currentYear) + "-01-01", Count: 2},
+	}}}
+	lifetime, err := NewClient("ghp_recent").lifetimeContributions(context.Background(), "octocat", recent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := currentYearQueries.Load(); n != 0 {
+		t.Errorf("expected the current year taken from the recent calendar, got %d queries", n)
+	}
+	if len(lifetime.Years) != 2 || lifetime.Years[1].Total != 2 || lifetime.Streak.TotalContributions != 6 {
+		t.Errorf("expected the past year once and 2 contributions this year, got %+v", lifetime.Years)
+	}
+}
@@ -0,0 +1,139 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrStatsPending = errors.New("stats are being computed")
+)
+
+// RateLimitError is returned when GitHub throttles a request or the local
+// budget tracker refuses to start one. It matches ErrRateLimited.
+type RateLimitError struct {
+	Resource  string
+	Reset     time.Time
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("GitHub %s %s exceeded", e.Resource, kind)
+	}
+	return fmt.Sprintf("GitHub %s %s exceeded until %s", e.Resource, kind, e.Reset.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// APIError is a REST response with an unexpected status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API error %d: %s", e.StatusCode, e.Message)
+}
+
+// GraphQLError is the first entry of the errors array of a GraphQL response.
+// Well-known types match the corresponding sentinel errors.
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Path    []any  `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e *GraphQLError) Error() string {
+	if e.Type == "" {
+		return "GraphQL error: " + e.Message
+	}
+	return fmt.Sprintf("GraphQL error (%s): %s", e.Type, e.Message)
+}
+
+func (e *GraphQLError) Is(target error) bool {
+	switch e.Type {
+	case "NOT_FOUND":
+		return target == ErrNotFound
+	case "RATE_LIMITED":
+		return target == ErrRateLimited
+	case "FORBIDDEN", "INSUFFICIENT_SCOPES":
+		return target == ErrUnauthorized
+	}
+	return false
+}
+
+// errorFromResponse converts a non-200 response into a typed error.
+func errorFromResponse(resp *http.Response, resource string) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusAccepted:
+		return ErrStatsPending
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	message := string(body)
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		message = parsed.Message
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if err := rateLimitErrorFromResponse(resp, resource, message); err != nil {
+			return err
+		}
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+func rateLimitErrorFromResponse(resp *http.Response, resource, message string) *RateLimitError {
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &RateLimitError{
+			Resource:  resource,
+			Reset:     time.Now().Add(time.Duration(seconds) * time.Second),
+			Secondary: true,
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		rateErr := &RateLimitError{Resource: resource}
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			rateErr.Reset = time.Unix(reset, 0)
+		}
+		return rateErr
+	}
+
+	lower := strings.ToLower(message)
+	if resp.StatusCode == http.StatusTooManyRequests || strings.Contains(lower, "rate limit") {
+		return &RateLimitError{
+			Resource:  resource,
+			Secondary: strings.Contains(lower, "secondary"),
+		}
+	}
+	return nil
+}
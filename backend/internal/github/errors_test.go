@@ -0,0 +1,101 @@
+package github
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestResponse(status int, body string, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestErrorFromResponse_Classification(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		resp   *http.Response
+		target error
+	}{
+		{"not found", newTestResponse(http.StatusNotFound, `{"message":"Not Found"}`, nil), ErrNotFound},
+		{"accepted", newTestResponse(http.StatusAccepted, "", nil), ErrStatsPending},
+		{"unauthorized", newTestResponse(http.StatusUnauthorized, `{"message":"Bad credentials"}`, nil), ErrUnauthorized},
+		{"primary rate limit", newTestResponse(http.StatusForbidden, `{"message":"API rate limit exceeded"}`, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		}), ErrRateLimited},
+		{"rate limit message only", newTestResponse(http.StatusForbidden, `{"message":"API rate limit exceeded"}`, nil), ErrRateLimited},
+		{"too many requests", newTestResponse(http.StatusTooManyRequests, "", nil), ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errorFromResponse(tt.resp, ResourceCore)
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected %v, got %v", tt.target, err)
+			}
+		})
+	}
+}
+
+func TestErrorFromResponse_RateLimitCarriesReset(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	resp := newTestResponse(http.StatusForbidden, `{"message":"API rate limit exceeded"}`, map[string]string{
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		"X-RateLimit-Resource":  "search",
+	})
+
+	var rateErr *RateLimitError
+	if !errors.As(errorFromResponse(resp, ResourceCore), &rateErr) {
+		t.Fatal("expected RateLimitError")
+	}
+	if rateErr.Reset.Unix() != reset {
+		t.Errorf("expected reset %d, got %d", reset, rateErr.Reset.Unix())
+	}
+	if rateErr.Resource != ResourceSearch {
+		t.Errorf("expected resource search, got %s", rateErr.Resource)
+	}
+}
+
+func TestErrorFromResponse_PlainForbiddenIsAPIError(t *testing.T) {
+	resp := newTestResponse(http.StatusForbidden, `{"message":"Resource protected by organization SAML enforcement"}`, nil)
+
+	err := errorFromResponse(resp, ResourceCore)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", apiErr.StatusCode)
+	}
+	if errors.Is(err, ErrRateLimited) {
+		t.Error("plain 403 should not be classified as rate limited")
+	}
+}
+
+func TestGraphQLError_MatchesSentinels(t *testing.T) {
+	notFound := &GraphQLError{Type: "NOT_FOUND", Message: "Could not resolve to a User"}
+	if !errors.Is(notFound, ErrNotFound) {
+		t.Error("expected NOT_FOUND to match ErrNotFound")
+	}
+
+	other := &GraphQLError{Type: "SOMETHING", Message: "user not found in cache"}
+	if errors.Is(other, ErrNotFound) {
+		t.Error("expected message text not to affect classification")
+	}
+}
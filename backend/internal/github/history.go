@@ -0,0 +1,301 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// historyBatchSize is the number of repositories queried per GraphQL request.
+	historyBatchSize = 5
+	// historyPageSize is the number of commits fetched per repository and request.
+	historyPageSize = 100
+	// historyWorkers bounds the GraphQL requests in flight for one crawl.
+	historyWorkers = 3
+)
+
+// historyCommit is a commit node of a GraphQL history connection.
+type historyCommit struct {
+	OID          string `json:"oid"`
+	Message      string `json:"message"`
+	AuthoredDate string `json:"authoredDate"`
+	URL          string `json:"url"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+	Author       struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		User  *struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"author"`
+}
+
+type historyRepo struct {
+	DefaultBranchRef *struct {
+		Target struct {
+			History struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []historyCommit `json:"nodes"`
+			} `json:"history"`
+		} `json:"target"`
+	} `json:"defaultBranchRef"`
+}
+
+// historyCursor tracks the pagination of one repository within a batch.
+// Commits are attributed to label, the repository's Key.
+type historyCursor struct {
+	owner string
+	repo  string
+	label string
+	after string
+	done  bool
+}
+
+// GetCommitHistory crawls the default branch history of repos with GraphQL,
+// querying several repositories per request. Unlike the REST listing it
+// includes line counts per commit. The author filter is applied server-side
+// unless co-authored commits are requested.
+func (c *Client) GetCommitHistory(ctx context.Context, owner string, repos []Repository, filter CommitFilter) ([]Commit, error) {
+	if len(repos) == 0 {
+		return []Commit{}, nil
+	}
+
+	batches := (len(repos) + historyBatchSize - 1) / historyBatchSize
+	if !c.CanAfford(ResourceGraphQL, batches) {
+		return nil, c.budgetError(ResourceGraphQL)
+	}
+
+	batchChan := make(chan []Repository, batches)
+	for i := 0; i < len(repos); i += historyBatchSize {
+		batchChan <- repos[i:min(i+historyBatchSize, len(repos))]
+	}
+	close(batchChan)
+
+	var (
+		mu         sync.Mutex
+		allCommits []Commit
+		firstErr   error
+		wg         sync.WaitGroup
+	)
+	for i := 0; i < min(historyWorkers, batches); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchChan {
+				if ctx.Err() != nil {
+					return
+				}
+				commits, err := c.crawlHistoryBatch(ctx, owner, batch, filter)
+				if err != nil && len(batch) > 1 && ctx.Err() == nil {
+					// One failing repository fails its whole query, so
+					// retry the batch one repository at a time.
+					commits, err = c.crawlHistoryRepos(ctx, owner, batch, filter)
+				}
+				mu.Lock()
+				allCommits = append(allCommits, commits...)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil && len(allCommits) == 0 {
+		return nil, firstErr
+	}
+	if firstErr != nil {
+		log.Printf("Warning: partial commit history for %s: %v", owner, firstErr)
+	}
+	return allCommits, nil
+}
+
+// crawlHistoryBatch pages through the histories of up to historyBatchSize
+// repositories, dropping repositories from the query as they are exhausted.
+func (c *Client) crawlHistoryBatch(ctx context.Context, owner string, repos []Repository, filter CommitFilter) ([]Commit, error) {
+	cursors := make([]*historyCursor, len(repos))
+	for i, repo := range repos {
+		cursors[i] = &historyCursor{owner: repo.Owner(owner), repo: repo.Name, label: repo.Key()}
+	}
+
+	var commits []Commit
+	for {
+		var pending []*historyCursor
+		for _, cur := range cursors {
+			if !cur.done {
+				pending = append(pending, cur)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		query, vars := historyQuery(pending, filter)
+		var result struct {
+			Data map[string]*historyRepo `json:"data"`
+		}
+		if err := c.graphqlWithVars(ctx, query, vars, &result); err != nil {
+			return commits, err
+		}
+
+		for i, cur := range pending {
+			repo := result.Data[fmt.Sprintf("r%d", i)]
+			if repo == nil || repo.DefaultBranchRef == nil {
+				cur.done = true
+				continue
+			}
+			history := repo.DefaultBranchRef.Target.History
+			for _, node := range history.Nodes {
+				commit := node.toCommit(cur.label)
+				if filter.IncludeCoAuthored {
+					if !filter.Matches(commit) {
+						continue
+					}
+					commit.CoAuthored = !strings.EqualFold(commit.AuthorLogin, filter.Login) && !filter.ownsEmail(commit.Email)
+				}
+				commits = append(commits, commit)
+			}
+			cur.after = history.PageInfo.EndCursor
+			cur.done = !history.PageInfo.HasNextPage || cur.after == ""
+		}
+	}
+	return commits, nil
+}
+
+// crawlHistoryRepos crawls repos one per query, so a repository that cannot
+// be queried only loses its own commits. It returns the first error.
+func (c *Client) crawlHistoryRepos(ctx context.Context, owner string, repos []Repository, filter CommitFilter) ([]Commit, error) {
+	var commits []Commit
+	var firstErr error
+	for _, repo := range repos {
+		repoCommits, err := c.crawlHistoryBatch(ctx, owner, []Repository{repo}, filter)
+		commits = append(commits, repoCommits...)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return commits, firstErr
+}
+
+// historyQuery builds a query with one aliased repository field (r0, r1, ...)
+// per pending cursor. Owners, names and cursors are passed as variables.
+func historyQuery(pending []*historyCursor, filter CommitFilter) (string, map[string]any) {
+	vars := map[string]any{}
+	var params []string
+
+	filterArgs := ""
+	if author := filter.historyAuthor(); author != nil {
+		vars["author"] = author
+		params = append(params, "$author: CommitAuthor")
+		filterArgs = ", author: $author"
+	}
+	if !filter.Since.IsZero() {
+		vars["since"] = filter.Since.UTC().Format(time.RFC3339)
+		params = append(params, "$since: GitTimestamp")
+		filterArgs += ", since: $since"
+	}
+
+	var fields strings.Builder
+	for i, cur := range pending {
+		vars[fmt.Sprintf("o%d", i)] = cur.owner
+		vars[fmt.Sprintf("n%d", i)] = cur.repo
+		params = append(params, fmt.Sprintf("$o%d: String!", i), fmt.Sprintf("$n%d: String!", i))
+		afterArg := ""
+		if cur.after != "" {
+			vars[fmt.Sprintf("c%d", i)] = cur.after
+			params = append(params, fmt.Sprintf("$c%d: String", i))
+			afterArg = fmt.Sprintf(", after: $c%d", i)
+		}
+		fmt.Fprintf(&fields, `
+		r%d: repository(owner: $o%d, name: $n%d) {
+			defaultBranchRef {
+				target {
+					... on Commit {
+						history(first: %d%s%s) {
+							pageInfo { hasNextPage endCursor }
+							nodes {
+								oid
+								message
+								authoredDate
+								url
+								additions
+								deletions
+								author { name email user { login } }
+							}
+						}
+					}
+				}
+			}
+		}`, i, i, i, historyPageSize, afterArg, filterArgs)
+	}
+
+	query := fmt.Sprintf("query(%s) {%s\n\t}", strings.Join(params, ", "), fields.String())
+	return query, vars
+}
+
+// historyAuthor is the CommitAuthor input for the filter. GraphQL gives the
+// user ID precedence over emails, so emails are only sent without an ID.
+// Co-authored commits are filtered client-side and need the full history.
+func (f CommitFilter) historyAuthor() map[string]any {
+	if f.IsZero() || f.IncludeCoAuthored {
+		return nil
+	}
+	if f.UserID != "" {
+		return map[string]any{"id": f.UserID}
+	}
+	if len(f.Emails) > 0 {
+		return map[string]any{"emails": f.Emails}
+	}
+	return nil
+}
+
+func (n historyCommit) toCommit(repo string) Commit {
+	date, _ := time.Parse(time.RFC3339, n.AuthoredDate)
+	commit := Commit{
+		SHA:       n.OID,
+		Message:   n.Message,
+		Author:    n.Author.Name,
+		Email:     n.Author.Email,
+		Date:      date,
+		URL:       n.URL,
+		Repo:      repo,
+		Additions: n.Additions,
+		Deletions: n.Deletions,
+	}
+	if n.Author.User != nil {
+		commit.AuthorLogin = n.Author.User.Login
+	}
+	return commit
+}
+
+// canCrawlHistory reports whether filter can be applied by the GraphQL
+// crawler, which needs a token and, for author filtering, a user ID or an
+// email to filter by.
+func (c *Client) canCrawlHistory(filter CommitFilter) bool {
+	if c.token == "" {
+		return false
+	}
+	return filter.IsZero() || filter.IncludeCoAuthored || filter.historyAuthor() != nil
+}
+
+// isFallbackError reports whether a failed GraphQL crawl should be retried
+// over REST rather than reported.
+func isFallbackError(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func historyPage(hasNext bool, cursor string, oids ...string) map[string]any {
+	nodes := make([]map[string]any, len(oids))
+	for i, oid := range oids {
+		nodes[i] = map[string]any{
+			"oid":          oid,
+			"authoredDate": "2025-01-01T10:00:00Z",
+			"additions":    10,
+			"deletions":    2,
+			"author":       map[string]any{"name": "Octo", "email": "octo@example.com", "user": map[string]any{"login": "octocat"}},
+		}
+	}
+	return map[string]any{"defaultBranchRef": map[string]any{"target": map[string]any{"history": map[string]any{
+		"pageInfo": map[string]any{"hasNextPage": hasNext, "endCursor": cursor},
+		"nodes":    nodes,
+	}}}}
+}
+
+func TestClient_GetCommitHistory_PaginatesBatchedRepos(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if author, ok := body.Variables["author"].(map[string]any); !ok || author["id"] != "U_1" {
+			t.Errorf("expected author filter by user ID, got %v", body.Variables["author"])
+		}
+
+		data := map[string]any{}
+		if _, ok := body.Variables["c0"]; ok {
+			// Second round: only repo1 has more history.
+			if strings.Contains(body.Query, "r1:") {
+				t.Error("expected exhausted repos to be dropped from the query")
+			}
+			data["r0"] = historyPage(false, "", "a2")
+		} else {
+			data["r0"] = historyPage(true, "cursor1", "a1")
+			data["r1"] = historyPage(false, "", "b1")
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	original := SetGraphQLURL(server.URL)
+	defer SetGraphQLURL(original)
+
+	repos := []Repository{{Name: "repo1"}, {Name: "repo2"}}
+	filter := CommitFilter{Login: "octocat", UserID: "U_1"}
+	commits, err := NewClient("ghp_history").GetCommitHistory(context.Background(), "octocat", repos, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 GraphQL requests, got %d", requests.Load())
+	}
+	if len(commits) != 3 {
+		t.Fatalf("expected 3 commits, got %d", len(commits))
+	}
+	for _, c := range commits {
+		if c.Additions != 10 || c.Deletions != 2 {
+			t.Errorf("expected line counts, got +%d -%d", c.Additions, c.Deletions)
+		}
+		if c.AuthorLogin != "octocat" {
+			t.Errorf("expected author login octocat, got %q", c.AuthorLogin)
+		}
+	}
+}
+
+func TestClient_GetCommitHistory_RetriesFailedBatchPerRepo(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		data := map[string]any{}
+		for i := 0; ; i++ {
+			name, ok := body.Variables[fmt.Sprintf("n%d", i)]
+			if !ok {
+				break
+			}
+			if name == "broken" {
+				json.NewEncoder(w).Encode(map[string]any{
+					"errors": []map[string]any{{"type": "INTERNAL", "message": "boom"}},
+				})
+				return
+			}
+			data[fmt.Sprintf("r%d", i)] = historyPage(false, "", name.(string)+"-1")
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	original := SetGraphQLURL(server.URL)
+	defer SetGraphQLURL(original)
+
+	repos := []Repository{{Name: "repo1"}, {Name: "broken"}, {Name: "repo3"}}
+	commits, err := NewClient("ghp_history").GetCommitHistory(context.Background(), "octocat", repos, CommitFilter{Login: "octocat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("expected the commits of the 2 working repos, got %d", len(commits))
+	}
+	if requests.Load() != 4 {
+		t.Errorf("expected the batch and 3 single-repo retries, got %d requests", requests.Load())
+	}
+}
+
+func TestClient_GetAllAuthoredCommits_FallsBackToREST(t *testing.T) {
+	var restCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"errors": []map[string]any{{"type": "INTERNAL", "message": "boom"}},
+			})
+			return
+		}
+		restCalls.Add(1)
+		json.NewEncoder(w).Encode([]map[string]any{{"sha": "rest1"}})
+	}))
+	defer server.Close()
+
+	originalAPI := SetAPIURL(server.URL)
+	defer SetAPIURL(originalAPI)
+	originalGraphQL := SetGraphQLURL(server.URL + "/graphql")
+	defer SetGraphQLURL(originalGraphQL)
+
+	repos := []Repository{{Name: "repo1"}}
+	commits, err := NewClient("ghp_fallback").GetAllAuthoredCommits(context.Background(), "octocat", repos, 0, CommitFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restCalls.Load() == 0 {
+		t.Error("expected REST fallback after GraphQL failure")
+	}
+	if len(commits) != 1 || commits[0].SHA != "rest1" {
+		t.Errorf("expected REST commits, got %+v", commits)
+	}
+}
+
+func TestClient_CanCrawlHistory(t *testing.T) {
+	client := NewClient("ghp_token")
+
+	if NewPublicClient().canCrawlHistory(CommitFilter{}) {
+		t.Error("expected GraphQL crawl to require a token")
+	}
+	if !client.canCrawlHistory(CommitFilter{Login: "octocat", UserID: "U_1"}) {
+		t.Error("expected crawl with a user ID")
+	}
+	if client.canCrawlHistory(CommitFilter{Login: "octocat"}) {
+		t.Error("expected login-only filters to use REST")
+	}
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"container/list"
+	"sync"
+)
+
+// responseCacheBytes bounds the memory held by cached REST response bodies.
+const responseCacheBytes = 64 << 20
+
+// cachedResponse is a REST response body together with the validators needed
+// to revalidate it with a conditional request.
+type cachedResponse struct {
+	key          string
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// responseCache is an LRU of REST responses keyed by token identity and URL.
+// GitHub does not count 304 Not Modified responses against the rate limit,
+// so revalidating cached responses keeps refreshes almost free.
+type responseCache struct {
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	size     int
+	maxBytes int
+}
+
+var responses = newResponseCache(responseCacheBytes)
+
+func newResponseCache(maxBytes int) *responseCache {
+	return &responseCache{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		maxBytes: maxBytes,
+	}
+}
+
+// responseCacheKey keeps responses of different tokens apart so private data
+// fetched with one token is never served to another.
+func responseCacheKey(token, url string) string {
+	return tokenIdentity(token) + " " + url
+}
+
+func (c *responseCache) get(key string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cachedResponse)
+}
+
+func (c *responseCache) set(entry *cachedResponse) {
+	if len(entry.body) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.key]; ok {
+		c.size -= len(el.Value.(*cachedResponse).body)
+		el.Value = entry
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[entry.key] = c.order.PushFront(entry)
+	}
+	c.size += len(entry.body)
+
+	for c.size > c.maxBytes {
+		oldest := c.order.Back()
+		evicted := c.order.Remove(oldest).(*cachedResponse)
+		delete(c.entries, evicted.key)
+		c.size -= len(evicted.body)
+	}
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_RevalidatesWithETag(t *testing.T) {
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(map[string]any{"login": "etaguser", "followers": 42})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	client := NewClient("ghp_etag")
+	for i := 0; i < 3; i++ {
+		profile, err := client.GetProfile(context.Background(), "etaguser")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if profile.Followers != 42 {
+			t.Errorf("expected 42 followers, got %d", profile.Followers)
+		}
+	}
+
+	if full.Load() != 1 {
+		t.Errorf("expected 1 full response, got %d", full.Load())
+	}
+	if notModified.Load() != 2 {
+		t.Errorf("expected 2 conditional hits, got %d", notModified.Load())
+	}
+}
+
+func TestClient_ResponseCacheIsPerToken(t *testing.T) {
+	var conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(map[string]any{"login": "shared"})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	NewClient("ghp_first").GetProfile(context.Background(), "shared")
+	NewClient("ghp_second").GetProfile(context.Background(), "shared")
+
+	if conditional.Load() != 0 {
+		t.Errorf("expected no conditional requests across tokens, got %d", conditional.Load())
+	}
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResponseCache(10)
+	cache.set(&cachedResponse{key: "a", body: []byte("12345")})
+	cache.set(&cachedResponse{key: "b", body: []byte("12345")})
+	cache.get("a")
+	cache.set(&cachedResponse{key: "c", body: []byte("12345")})
+
+	if cache.get("b") != nil {
+		t.Error("expected b to be evicted")
+	}
+	if cache.get("a") == nil || cache.get("c") == nil {
+		t.Error("expected a and c to remain cached")
+	}
+	if cache.size != 10 {
+		t.Errorf("expected size 10, got %d", cache.size)
+	}
+}
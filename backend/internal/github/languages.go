@@ -0,0 +1,362 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxLanguages is the number of languages listed by size before the
+	// rest is grouped as "Other".
+	maxLanguages = 8
+	otherColor   = "#8b8b8b"
+)
+
+// percentage returns part of total in percent, rounded to one decimal.
+func percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
+
+// CalculateLanguageBytes weighs languages by the bytes of code GitHub
+// detected in each of repos, paging through all of the user's repositories.
+// Languages beyond the top maxLanguages are grouped as "Other".
+func (c *Client) CalculateLanguageBytes(ctx context.Context, username string, repos []Repository) ([]LanguageStats, error) {
+	sizes, colors, err := c.languageSizes(ctx, username, repos)
+	if err != nil || sizes == nil {
+		return nil, err
+	}
+	return languagesBySize(sizes, colors), nil
+}
+
+// languageSizes sums the bytes per language of repos and collects the
+// languages' GitHub colors, in one paginated query. Both are nil without
+// repos.
+func (c *Client) languageSizes(ctx context.Context, username string, repos []Repository) (map[string]int64, map[string]string, error) {
+	if len(repos) == 0 {
+		return nil, nil, nil
+	}
+
+	// Only count the repositories the stats were computed for, so the
+	// visibility, fork and affiliation filters of the caller apply.
+	// Repositories the user merely contributed to without being a member
+	// are not listed by this query and count by primary language only.
+	wanted := make(map[string]bool, len(repos))
+	affiliations := []string{"OWNER"}
+	for _, repo := range repos {
+		wanted[strings.ToLower(repo.Owner(username)+"/"+repo.Name)] = true
+		if repo.Affiliation != "" {
+			affiliations = []string{"OWNER", "COLLABORATOR", "ORGANIZATION_MEMBER"}
+		}
+	}
+
+	query := `query($login: String!, $cursor: String, $affiliations: [RepositoryAffiliation]) {
+		user(login: $login) {
+			repositories(first: 100, after: $cursor, ownerAffiliations: $affiliations) {
+				pageInfo { hasNextPage endCursor }
+				nodes {
+					nameWithOwner
+					languages(first: 100, orderBy: {field: SIZE, direction: DESC}) {
+						edges {
+							size
+							node { name color }
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	sizes := make(map[string]int64)
+	colors := make(map[string]string)
+	vars := map[string]any{"login": username, "affiliations": affiliations}
+
+	for {
+		var result struct {
+			Data struct {
+				User *struct {
+					Repositories struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							NameWithOwner string `json:"nameWithOwner"`
+							Languages     struct {
+								Edges []struct {
+									Size int64 `json:"size"`
+									Node struct {
+										Name  string `json:"name"`
+										Color string `json:"color"`
+									} `json:"node"`
+								} `json:"edges"`
+							} `json:"languages"`
+						} `json:"nodes"`
+					} `json:"repositories"`
+				} `json:"user"`
+			} `json:"data"`
+		}
+
+		if err := c.graphqlWithVars(ctx, query, vars, &result); err != nil {
+			return nil, nil, err
+		}
+		if result.Data.User == nil {
+			return nil, nil, ErrNotFound
+		}
+
+		page := result.Data.User.Repositories
+		for _, repo := range page.Nodes {
+			if !wanted[strings.ToLower(repo.NameWithOwner)] {
+				continue
+			}
+			for _, edge := range repo.Languages.Edges {
+				sizes[edge.Node.Name] += edge.Size
+				if edge.Node.Color != "" {
+					colors[edge.Node.Name] = edge.Node.Color
+				}
+			}
+		}
+
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			break
+		}
+		vars["cursor"] = page.PageInfo.EndCursor
+	}
+
+	return sizes, colors, nil
+}
+
+// languagesBySize turns byte totals into percentages, largest first, with
+// everything past maxLanguages grouped as "Other".
+func languagesBySize(sizes map[string]int64, colors map[string]string) []LanguageStats {
+	var total int64
+	stats := make([]LanguageStats, 0, len(sizes))
+	for name, size := range sizes {
+		if size <= 0 {
+			continue
+		}
+		total += size
+		color := colors[name]
+		if color == "" {
+			color = LanguageColor(name)
+		}
+		stats = append(stats, LanguageStats{Name: name, Color: color, Bytes: size})
+	}
+	if total == 0 {
+		return nil
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Bytes != stats[j].Bytes {
+			return stats[i].Bytes > stats[j].Bytes
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	if len(stats) > maxLanguages {
+		other := LanguageStats{Name: "Other", Color: otherColor}
+		for _, lang := range stats[maxLanguages:] {
+			other.Bytes += lang.Bytes
+		}
+		stats = append(stats[:maxLanguages], other)
+	}
+
+	for i := range stats {
+		stats[i].Percentage = percentage(stats[i].Bytes, total)
+	}
+	return stats
+}
+
+// maxFileCommits bounds the commits whose changed files are fetched, as
+// each needs its own request.
+const maxFileCommits = 200
+
+// extensionLanguages maps file extensions to the languages they are
+// written in, for attributing changed files.
+var extensionLanguages = map[string]string{
+	".go":     "Go",
+	".js":     "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".jsx":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".py":     "Python",
+	".java":   "Java",
+	".rs":     "Rust",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".swift":  "Swift",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".scala":  "Scala",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".clj":    "Clojure",
+	".hs":     "Haskell",
+	".lua":    "Lua",
+	".r":      "R",
+	".jl":     "Julia",
+	".pl":     "Perl",
+	".m":      "Objective-C",
+	".vim":    "Vim Script",
+	".ps1":    "PowerShell",
+	".tf":     "HCL",
+	".hcl":    "HCL",
+	".nix":    "Nix",
+	".zig":    "Zig",
+	".nim":    "Nim",
+	".ml":     "OCaml",
+	".fs":     "F#",
+	".erl":    "Erlang",
+	".asm":    "Assembly",
+	".yml":    "YAML",
+	".yaml":   "YAML",
+	".json":   "JSON",
+	".md":     "Markdown",
+	".tex":    "TeX",
+}
+
+// LanguageForFile returns the language of a file by its name, or "" if it
+// is not recognised.
+func LanguageForFile(filename string) string {
+	base := path.Base(filename)
+	switch base {
+	case "Dockerfile":
+		return "Dockerfile"
+	case "Makefile":
+		return "Makefile"
+	}
+	return extensionLanguages[strings.ToLower(path.Ext(base))]
+}
+
+// LanguageColor returns the usual color of a language.
+func LanguageColor(name string) string {
+	if color := defaultLanguageColors[name]; color != "" {
+		return color
+	}
+	return otherColor
+}
+
+// commitFilesCacheBytes bounds the memory held by cached commit file lists.
+const commitFilesCacheBytes = 32 << 20
+
+// commitFiles caches the files changed by commits, which never change, so
+// repeated trend requests don't refetch them. Entries are kept per token
+// like REST responses.
+var commitFiles = newResponseCache(commitFilesCacheBytes)
+
+// GetCommitFiles returns the files changed by a commit.
+func (c *Client) GetCommitFiles(ctx context.Context, owner, repo, sha string) ([]CommitFile, error) {
+	endpoint := fmt.Sprintf("/repos/%s/%s/commits/%s", owner, repo, sha)
+	key := responseCacheKey(c.token, endpoint)
+	if cached := commitFiles.get(key); cached != nil {
+		var files []CommitFile
+		if err := json.Unmarshal(cached.body, &files); err == nil {
+			return files, nil
+		}
+	}
+
+	var response struct {
+		Files []CommitFile `json:"files"`
+	}
+	if err := c.request(ctx, endpoint, &response); err != nil {
+		return nil, err
+	}
+	if body, err := json.Marshal(response.Files); err == nil {
+		commitFiles.set(&cachedResponse{key: key, body: body})
+	}
+	return response.Files, nil
+}
+
+// GetFilesForCommits fetches the changed files of up to maxFileCommits of
+// commits, keyed by SHA. Commits that fail are left out.
+func (c *Client) GetFilesForCommits(ctx context.Context, owner string, commits []Commit) (map[string][]CommitFile, error) {
+	if len(commits) > maxFileCommits {
+		commits = commits[:maxFileCommits]
+	}
+	if len(commits) == 0 {
+		return map[string][]CommitFile{}, nil
+	}
+	if !c.CanAfford(ResourceCore, len(commits)) {
+		return nil, c.budgetError(ResourceCore)
+	}
+
+	const maxWorkers = 10
+	numWorkers := min(maxWorkers, len(commits))
+
+	type result struct {
+		sha   string
+		files []CommitFile
+		err   error
+	}
+
+	commitChan := make(chan Commit, len(commits))
+	resultChan := make(chan result, len(commits))
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for commit := range commitChan {
+				if ctx.Err() != nil {
+					resultChan <- result{err: ctx.Err()}
+					continue
+				}
+				// Commits of repositories owned by others are labelled "owner/name".
+				repoOwner, repo := owner, commit.Repo
+				if o, name, ok := strings.Cut(commit.Repo, "/"); ok {
+					repoOwner, repo = o, name
+				}
+				files, err := c.GetCommitFiles(ctx, repoOwner, repo, commit.SHA)
+				resultChan <- result{sha: commit.SHA, files: files, err: err}
+			}
+		}()
+	}
+
+	for _, commit := range commits {
+		commitChan <- commit
+	}
+	close(commitChan)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	files := make(map[string][]CommitFile, len(commits))
+	for res := range resultChan {
+		if res.err != nil {
+			continue
+		}
+		files[res.sha] = res.files
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
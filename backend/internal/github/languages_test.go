@@ -0,0 +1,190 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPercentage_RoundsToOneDecimal(t *testing.T) {
+	if got := percentage(1, 3); got != 33.3 {
+		t.Errorf("percentage(1, 3) = %v, want 33.3", got)
+	}
+	if got := percentage(1, 0); got != 0 {
+		t.Errorf("percentage(1, 0) = %v, want 0", got)
+	}
+}
+
+func TestLanguagesBySize_GroupsOther(t *testing.T) {
+	sizes := map[string]int64{}
+	for i := 0; i < maxLanguages+3; i++ {
+		sizes[fmt.Sprintf("Lang%02d", i)] = int64(1000 - i)
+	}
+
+	stats := languagesBySize(sizes, map[string]string{"Lang00": "#123456"})
+
+	if len(stats) != maxLanguages+1 {
+		t.Fatalf("expected %d entries, got %d", maxLanguages+1, len(stats))
+	}
+	if stats[0].Name != "Lang00" || stats[0].Color != "#123456" {
+		t.Errorf("expected largest language first with its color, got %+v", stats[0])
+	}
+	other := stats[len(stats)-1]
+	if other.Name != "Other" || other.Bytes != 992+991+990 {
+		t.Errorf("unexpected Other bucket: %+v", other)
+	}
+
+	var sum float64
+	for _, lang := range stats {
+		sum += lang.Percentage
+	}
+	if math.Abs(sum-100) > 0.5 {
+		t.Errorf("expected percentages to sum to about 100, got %v", sum)
+	}
+}
+
+func TestClient_CalculateLanguageBytes_PaginatesAndFiltersRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		repo := func(name, lang string, size int) map[string]any {
+			return map[string]any{"nameWithOwner": "octocat/" + name, "languages": map[string]any{"edges": []map[string]any{
+				{"size": size, "node": map[string]any{"name": lang, "color": "#000000"}},
+			}}}
+		}
+		page := map[string]any{
+			"pageInfo": map[string]any{"hasNextPage": true, "endCursor": "next"},
+			"nodes":    []map[string]any{repo("monorepo", "Go", 200000), repo("private", "Rust", 999999)},
+		}
+		if body.Variables["cursor"] == "next" {
+			page = map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes":    []map[string]any{repo("scripts", "Shell", 50)},
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{"repositories": page}}})
+	}))
+	defer server.Close()
+
+	original := SetGraphQLURL(server.URL)
+	defer SetGraphQLURL(original)
+
+	repos := []Repository{{Name: "monorepo"}, {Name: "scripts"}}
+	stats, err := NewClient("ghp_bytes").CalculateLanguageBytes(context.Background(), "octocat", repos)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("expected Go and Shell only, got %+v", stats)
+	}
+	if stats[0].Name != "Go" || stats[0].Bytes != 200000 || stats[0].Percentage != 100 {
+		t.Errorf("unexpected Go entry: %+v", stats[0])
+	}
+	if stats[1].Name != "Shell" || stats[1].Percentage != 0 {
+		t.Errorf("unexpected Shell entry: %+v", stats[1])
+	}
+}
+
+func TestLanguageForFile(t *testing.T) {
+	tests := map[string]string{
+		"cmd/server/main.go": "Go",
+		"src/App.TSX":        "TypeScript",
+		"build/Dockerfile":   "Dockerfile",
+		"LICENSE":            "",
+	}
+	for filename, want := range tests {
+		if got := LanguageForFile(filename); got != want {
+			t.Errorf("LanguageForFile(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestClient_GetCommitFiles_CachesFileLists(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(map[string]any{
+			"files": []map[string]any{{"filename": "main.go", "additions": 3}},
+		})
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+
+	client := NewClient("ghp_files")
+	for i := 0; i < 2; i++ {
+		files, err := client.GetCommitFiles(context.Background(), "octocat", "hello", "cafe01")
+		if err != nil || len(files) != 1 || files[0].Filename != "main.go" {
+			t.Fatalf("unexpected files %v (%v)", files, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the file list to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestCountLanguages_FallsBackToKnownColors(t *testing.T) {
+	repos := []Repository{{Language: "Go"}, {Language: "Go"}, {Language: "Zig"}, {}}
+	stats := CountLanguages(repos, map[string]string{"Zig": "#ec915c"})
+
+	if len(stats) != 2 || stats[0].Name != "Go" || stats[0].Color != LanguageColor("Go") {
+		t.Fatalf("expected Go first with its known color, got %+v", stats)
+	}
+	if stats[1].Color != "#ec915c" {
+		t.Errorf("expected the given color for Zig, got %+v", stats[1])
+	}
+}
+
+func TestClient_GetStatsWithOptions_QueriesLanguagesOnce(t *testing.T) {
+	var languageQueries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/octocat":
+			json.NewEncoder(w).Encode(map[string]any{"login": "octocat"})
+		case "/users/octocat/repos":
+			json.NewEncoder(w).Encode([]map[string]any{{"name": "monorepo", "language": "Go"}})
+		case "/graphql":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), "languages(") {
+				json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": nil}})
+				return
+			}
+			languageQueries.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{"repositories": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes": []map[string]any{{"nameWithOwner": "octocat/monorepo", "languages": map[string]any{"edges": []map[string]any{
+					{"size": 1200, "node": map[string]any{"name": "Go", "color": "#123456"}},
+				}}}},
+			}}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+	defer SetGraphQLURL(SetGraphQLURL(server.URL + "/graphql"))
+
+	stats, err := NewClient("ghp_stats").GetStatsWithOptions(context.Background(), "octocat", RepoOptions{Visibility: "public"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := languageQueries.Load(); n != 1 {
+		t.Errorf("expected one language query, got %d", n)
+	}
+	if len(stats.Languages) != 1 || stats.Languages[0].Color != "#123456" {
+		t.Errorf("expected repo counts colored from the size query, got %+v", stats.Languages)
+	}
+	if len(stats.LanguagesByBytes) != 1 || stats.LanguagesByBytes[0].Bytes != 1200 {
+		t.Errorf("expected byte totals from the same query, got %+v", stats.LanguagesByBytes)
+	}
+}
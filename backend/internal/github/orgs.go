@@ -0,0 +1,138 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// maxMemberPages bounds the member listing of large organizations.
+	maxMemberPages = 10
+	// maxOrgRepoPages bounds the repository listing the same way.
+	maxOrgRepoPages = 10
+)
+
+// GetOrganization fetches an organization's profile.
+func (c *Client) GetOrganization(ctx context.Context, org string) (*Organization, error) {
+	var organization Organization
+	if err := c.request(ctx, "/orgs/"+org, &organization); err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// GetOrgMembers lists the members of an organization. Without a token of a
+// member only public memberships are visible.
+func (c *Client) GetOrgMembers(ctx context.Context, org string) ([]Profile, error) {
+	return c.listMembers(ctx, fmt.Sprintf("/orgs/%s/members", org))
+}
+
+// GetTeamMembers lists the members of the team with slug in org.
+func (c *Client) GetTeamMembers(ctx context.Context, org, slug string) ([]Profile, error) {
+	return c.listMembers(ctx, fmt.Sprintf("/orgs/%s/teams/%s/members", org, slug))
+}
+
+func (c *Client) listMembers(ctx context.Context, path string) ([]Profile, error) {
+	var members []Profile
+	for page := 1; page <= maxMemberPages; page++ {
+		var batch []Profile
+		if err := c.request(ctx, fmt.Sprintf("%s?per_page=100&page=%d", path, page), &batch); err != nil {
+			return members, err
+		}
+		members = append(members, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return members, nil
+}
+
+// GetOrgRepositories lists the non-fork, non-archived repositories of an
+// organization visible to the client, up to maxOrgRepoPages pages of the
+// most recently updated.
+func (c *Client) GetOrgRepositories(ctx context.Context, org string) ([]Repository, error) {
+	var allRepos []Repository
+	for page := 1; page <= maxOrgRepoPages; page++ {
+		var repos []Repository
+		endpoint := fmt.Sprintf("/orgs/%s/repos?type=all&sort=updated&per_page=100&page=%d", org, page)
+		if err := c.request(ctx, endpoint, &repos); err != nil {
+			return allRepos, err
+		}
+		for _, r := range repos {
+			if !r.Fork && !r.Archived {
+				allRepos = append(allRepos, r)
+			}
+		}
+		if len(repos) < 100 {
+			break
+		}
+	}
+	return allRepos, nil
+}
+
+// GetRepoLanguageBytes sums the language sizes of repos, which belong to
+// owner unless their full name says otherwise. Unlike CalculateLanguageBytes
+// it works for any owner and without a token, at one request per repository.
+func (c *Client) GetRepoLanguageBytes(ctx context.Context, owner string, repos []Repository) ([]LanguageStats, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+	if !c.CanAfford(ResourceCore, len(repos)) {
+		return nil, c.budgetError(ResourceCore)
+	}
+
+	const maxWorkers = 10
+	numWorkers := min(maxWorkers, len(repos))
+
+	type result struct {
+		sizes map[string]int64
+		err   error
+	}
+
+	repoChan := make(chan Repository, len(repos))
+	resultChan := make(chan result, len(repos))
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoChan {
+				if ctx.Err() != nil {
+					resultChan <- result{err: ctx.Err()}
+					continue
+				}
+				var sizes map[string]int64
+				endpoint := fmt.Sprintf("/repos/%s/%s/languages", repo.Owner(owner), repo.Name)
+				err := c.request(ctx, endpoint, &sizes)
+				resultChan <- result{sizes: sizes, err: err}
+			}
+		}()
+	}
+
+	for _, repo := range repos {
+		repoChan <- repo
+	}
+	close(repoChan)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	sizes := make(map[string]int64)
+	for res := range resultChan {
+		if res.err != nil {
+			continue
+		}
+		for name, size := range res.sizes {
+			sizes[name] += size
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return languagesBySize(sizes, nil), nil
+}
@@ -0,0 +1,113 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_GetTeamMembers_Paginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/teams/platform/members" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		count := 100
+		if r.URL.Query().Get("page") == "2" {
+			count = 3
+		}
+		members := make([]map[string]any, count)
+		for i := range members {
+			members[i] = map[string]any{"login": fmt.Sprintf("user%s-%d", r.URL.Query().Get("page"), i)}
+		}
+		json.NewEncoder(w).Encode(members)
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	members, err := NewClient("ghp_team").GetTeamMembers(context.Background(), "acme", "platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 103 {
+		t.Errorf("expected 103 members over two pages, got %d", len(members))
+	}
+}
+
+func TestClient_GetRepoLanguageBytes_SumsRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/api/languages":
+			json.NewEncoder(w).Encode(map[string]int{"Go": 300, "Shell": 100})
+		case "/repos/partner/sdk/languages":
+			json.NewEncoder(w).Encode(map[string]int{"Go": 600})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	repos := []Repository{{Name: "api"}, {Name: "sdk", FullName: "partner/sdk"}}
+	stats, err := NewClient("ghp_langbytes").GetRepoLanguageBytes(context.Background(), "acme", repos)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Name != "Go" || stats[0].Bytes != 900 || stats[0].Percentage != 90 {
+		t.Errorf("unexpected language sizes: %+v", stats)
+	}
+}
+
+func TestClient_GetOrgRepositories_CapsPages(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		repos := make([]map[string]any, 100)
+		for i := range repos {
+			repos[i] = map[string]any{"name": fmt.Sprintf("repo%s-%d", r.URL.Query().Get("page"), i)}
+		}
+		json.NewEncoder(w).Encode(repos)
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+
+	repos, err := NewClient("ghp_org").GetOrgRepositories(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages != maxOrgRepoPages || len(repos) != maxOrgRepoPages*100 {
+		t.Errorf("expected %d pages, got %d pages and %d repos", maxOrgRepoPages, pages, len(repos))
+	}
+}
+
+func TestClient_GetAuthoredCommits_LimitsToWindow(t *testing.T) {
+	var since string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		since = r.URL.Query().Get("since")
+		json.NewEncoder(w).Encode([]any{})
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+
+	window := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := NewClient("").GetAuthoredCommits(context.Background(), "acme", "api", CommitFilter{Since: window}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if since != "2025-03-01T00:00:00Z" {
+		t.Errorf("expected since=2025-03-01T00:00:00Z, got %q", since)
+	}
+
+	query, vars := historyQuery([]*historyCursor{{owner: "acme", repo: "api"}}, CommitFilter{Since: window})
+	if !strings.Contains(query, "since: $since") || vars["since"] != "2025-03-01T00:00:00Z" {
+		t.Errorf("expected the history query limited to the window, got %s %v", query, vars)
+	}
+}
@@ -0,0 +1,108 @@
+package github
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// preloadWorkers bounds the country rankings fetched concurrently. Sources
+// with tighter limits lower it by implementing preloadWorkers themselves.
+const preloadWorkers = 4
+
+// RunPreloader loads every available country ranking now and again every
+// rankingTTL until ctx is cancelled, so the global ranking covers all
+// countries regardless of which ones were requested.
+func (r *RankingService) RunPreloader(ctx context.Context) {
+	ticker := time.NewTicker(rankingTTL)
+	defer ticker.Stop()
+	for {
+		r.Preload(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Preload fetches every available country ranking and rebuilds the global
+// index once all are in. A country that fails keeps its cached ranking.
+func (r *RankingService) Preload(ctx context.Context) {
+	r.mu.RLock()
+	listed := !r.countriesUpdatedAt.IsZero()
+	r.mu.RUnlock()
+	if !listed {
+		r.refreshCountriesList()
+	}
+	countries := r.GetAvailableCountries()
+
+	started := time.Now()
+	r.mu.Lock()
+	r.preload = RankingLoadStatus{Running: true, Total: len(countries), Failed: []string{}, StartedAt: &started}
+	r.mu.Unlock()
+
+	countryChan := make(chan string, len(countries))
+	for _, country := range countries {
+		countryChan <- country
+	}
+	close(countryChan)
+
+	workers := preloadWorkers
+	if source, ok := r.currentSource().(interface{ preloadWorkers() int }); ok {
+		workers = source.preloadWorkers()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(countries)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for country := range countryChan {
+				if ctx.Err() != nil {
+					return
+				}
+				fetchCtx, cancel := context.WithTimeout(ctx, rankingFetchTimeout)
+				ranking, err := r.fetchCountryRanking(fetchCtx, normalizeCountryName(country))
+				cancel()
+				if err == nil {
+					r.storeRanking(ranking, false)
+				} else {
+					log.Printf("Failed to preload %s ranking: %v", country, err)
+				}
+
+				r.mu.Lock()
+				if err == nil {
+					r.preload.Loaded++
+				} else {
+					r.preload.Failed = append(r.preload.Failed, country)
+				}
+				r.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	finished := time.Now()
+	r.mu.Lock()
+	r.rebuildGlobalIndex()
+	r.preload.Running = false
+	r.preload.FinishedAt = &finished
+	status := r.preload
+	users := len(r.globalIndex)
+	r.mu.Unlock()
+
+	log.Printf("Preloaded rankings: %d of %d countries, %d users, in %s",
+		status.Loaded, status.Total, users, finished.Sub(started).Round(time.Second))
+}
+
+// LoadStatus reports the progress of the current or last preload.
+func (r *RankingService) LoadStatus() RankingLoadStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	status := r.preload
+	status.Failed = append([]string{}, r.preload.Failed...)
+	status.GlobalTotal = len(r.globalIndex)
+	return status
+}
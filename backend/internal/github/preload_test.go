@@ -0,0 +1,54 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRankingService_Preload(t *testing.T) {
+	users := map[string][]CountryUser{
+		"lithuania": {{Login: "jonas", PublicContributions: 300}, {Login: "ona", PublicContributions: 100}},
+		"latvia":    {{Login: "anna", PublicContributions: 200}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		country := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json")
+		if list, ok := users[country]; ok {
+			json.NewEncoder(w).Encode(list)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	service := NewRankingService()
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
+		return http.Get(server.URL + req.URL.Path[strings.LastIndex(req.URL.Path, "/"):])
+	}
+	service.mu.Lock()
+	service.availableCountries = []string{"latvia", "lithuania", "atlantis"}
+	service.countriesUpdatedAt = time.Now()
+	service.mu.Unlock()
+
+	service.Preload(context.Background())
+
+	status := service.LoadStatus()
+	if status.Running || status.Total != 3 || status.Loaded != 2 || status.FinishedAt == nil {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if len(status.Failed) != 1 || status.Failed[0] != "atlantis" {
+		t.Errorf("expected atlantis to fail, got %v", status.Failed)
+	}
+	if status.GlobalTotal != 3 {
+		t.Errorf("expected 3 users in the global ranking, got %d", status.GlobalTotal)
+	}
+
+	ranking, _ := service.FindUserRanking("anna")
+	if ranking == nil || ranking.Country != "latvia" || ranking.GlobalRank != 2 {
+		t.Errorf("expected anna second globally in latvia, got %+v", ranking)
+	}
+}
@@ -0,0 +1,347 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPullRequestPages bounds the pull requests sampled for metrics to the
+// most recent 500.
+const maxPullRequestPages = 5
+
+// maxReviewPages bounds the further reviews read, 100 per page, for a pull
+// request whose first reviews are all replies by its author.
+const maxReviewPages = 3
+
+// reviewConnection is a page of a pull request's reviews.
+type reviewConnection struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []struct {
+		CreatedAt time.Time `json:"createdAt"`
+		Author    *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+	} `json:"nodes"`
+}
+
+// firstReview returns when the first review by someone other than author was
+// left, or the zero time if the page holds none. Replies to reviews by the
+// author do not count as a review.
+func (rc reviewConnection) firstReview(author string) time.Time {
+	for _, review := range rc.Nodes {
+		if review.Author != nil && strings.EqualFold(review.Author.Login, author) {
+			continue
+		}
+		return review.CreatedAt
+	}
+	return time.Time{}
+}
+
+// pullRequest is the data needed per pull request for PullRequestMetrics.
+type pullRequest struct {
+	CreatedAt   time.Time
+	MergedAt    time.Time
+	ClosedAt    time.Time
+	FirstReview time.Time
+}
+
+// GetContributionTotals returns a user's contribution counts by kind for a
+// calendar year, or for the last year if year is 0.
+func (c *Client) GetContributionTotals(ctx context.Context, username string, year int) (ContributionTotals, error) {
+	var dateRange string
+	if year > 0 {
+		dateRange = fmt.Sprintf(`(from: "%d-01-01T00:00:00Z", to: "%d-12-31T23:59:59Z")`, year, year)
+	}
+
+	query := fmt.Sprintf(`query($login: String!) {
+		user(login: $login) {
+			contributionsCollection%s {
+				totalCommitContributions
+				totalIssueContributions
+				totalPullRequestContributions
+				totalPullRequestReviewContributions
+			}
+		}
+	}`, dateRange)
+
+	var result struct {
+		Data struct {
+			User *struct {
+				ContributionsCollection struct {
+					TotalCommitContributions            int `json:"totalCommitContributions"`
+					TotalIssueContributions             int `json:"totalIssueContributions"`
+					TotalPullRequestContributions       int `json:"totalPullRequestContributions"`
+					TotalPullRequestReviewContributions int `json:"totalPullRequestReviewContributions"`
+				} `json:"contributionsCollection"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+
+	if err := c.graphqlWithVars(ctx, query, map[string]any{"login": username}, &result); err != nil {
+		return ContributionTotals{}, err
+	}
+	if result.Data.User == nil {
+		return ContributionTotals{}, fmt.Errorf("user %s: %w", username, ErrNotFound)
+	}
+
+	cc := result.Data.User.ContributionsCollection
+	return ContributionTotals{
+		Commits:            cc.TotalCommitContributions,
+		Issues:             cc.TotalIssueContributions,
+		PullRequests:       cc.TotalPullRequestContributions,
+		PullRequestReviews: cc.TotalPullRequestReviewContributions,
+	}, nil
+}
+
+// getPullRequests returns the user's most recent pull requests with the
+// time of their first review by someone else.
+func (c *Client) getPullRequests(ctx context.Context, username string) ([]pullRequest, error) {
+	query := `query($login: String!, $cursor: String) {
+		user(login: $login) {
+			pullRequests(first: 100, after: $cursor, orderBy: {field: CREATED_AT, direction: DESC}) {
+				pageInfo { hasNextPage endCursor }
+				nodes {
+					id
+					createdAt
+					mergedAt
+					closedAt
+					reviews(first: 5) {
+						pageInfo { hasNextPage endCursor }
+						nodes {
+							createdAt
+							author { login }
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	var prs []pullRequest
+	vars := map[string]any{"login": username}
+
+	for page := 0; page < maxPullRequestPages; page++ {
+		var result struct {
+			Data struct {
+				User *struct {
+					PullRequests struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							ID        string           `json:"id"`
+							CreatedAt time.Time        `json:"createdAt"`
+							MergedAt  *time.Time       `json:"mergedAt"`
+							ClosedAt  *time.Time       `json:"closedAt"`
+							Reviews   reviewConnection `json:"reviews"`
+						} `json:"nodes"`
+					} `json:"pullRequests"`
+				} `json:"user"`
+			} `json:"data"`
+		}
+
+		if err := c.graphqlWithVars(ctx, query, vars, &result); err != nil {
+			return prs, err
+		}
+		if result.Data.User == nil {
+			return nil, fmt.Errorf("user %s: %w", username, ErrNotFound)
+		}
+
+		connection := result.Data.User.PullRequests
+		for _, node := range connection.Nodes {
+			pr := pullRequest{CreatedAt: node.CreatedAt}
+			if node.MergedAt != nil {
+				pr.MergedAt = *node.MergedAt
+			}
+			if node.ClosedAt != nil {
+				pr.ClosedAt = *node.ClosedAt
+			}
+			pr.FirstReview = node.Reviews.firstReview(username)
+			if pr.FirstReview.IsZero() && node.Reviews.PageInfo.HasNextPage {
+				first, err := c.firstReviewAfter(ctx, node.ID, username, node.Reviews.PageInfo.EndCursor)
+				if err != nil {
+					return prs, err
+				}
+				pr.FirstReview = first
+			}
+			prs = append(prs, pr)
+		}
+
+		if !connection.PageInfo.HasNextPage || connection.PageInfo.EndCursor == "" {
+			break
+		}
+		vars["cursor"] = connection.PageInfo.EndCursor
+	}
+	return prs, nil
+}
+
+// firstReviewAfter pages through the reviews of pull request id after cursor
+// until one by someone other than author is found. It returns the zero time
+// if there is none within maxReviewPages.
+func (c *Client) firstReviewAfter(ctx context.Context, id, author, cursor string) (time.Time, error) {
+	query := `query($id: ID!, $cursor: String) {
+		node(id: $id) {
+			... on PullRequest {
+				reviews(first: 100, after: $cursor) {
+					pageInfo { hasNextPage endCursor }
+					nodes {
+						createdAt
+						author { login }
+					}
+				}
+			}
+		}
+	}`
+
+	vars := map[string]any{"id": id, "cursor": cursor}
+	for page := 0; page < maxReviewPages; page++ {
+		var result struct {
+			Data struct {
+				Node *struct {
+					Reviews reviewConnection `json:"reviews"`
+				} `json:"node"`
+			} `json:"data"`
+		}
+		if err := c.graphqlWithVars(ctx, query, vars, &result); err != nil {
+			return time.Time{}, err
+		}
+		if result.Data.Node == nil {
+			return time.Time{}, nil
+		}
+
+		reviews := result.Data.Node.Reviews
+		if first := reviews.firstReview(author); !first.IsZero() {
+			return first, nil
+		}
+		if !reviews.PageInfo.HasNextPage || reviews.PageInfo.EndCursor == "" {
+			break
+		}
+		vars["cursor"] = reviews.PageInfo.EndCursor
+	}
+	return time.Time{}, nil
+}
+
+// GetPullStats returns contribution totals for every contribution year
+// together with merge and review metrics of the user's recent pull requests.
+func (c *Client) GetPullStats(ctx context.Context, username string) (*PullStats, error) {
+	years, err := c.GetContributionYears(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]ContributionTotals, len(years))
+	errs := make([]error, len(years)+1)
+	var prs []pullRequest
+
+	sem := make(chan struct{}, yearWorkers)
+	var wg sync.WaitGroup
+	for i, year := range years {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			totals[i], errs[i] = c.GetContributionTotals(ctx, username, year)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		prs, errs[len(years)] = c.getPullRequests(ctx, username)
+	}()
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byYear := make(map[int][]pullRequest)
+	for _, pr := range prs {
+		byYear[pr.CreatedAt.Year()] = append(byYear[pr.CreatedAt.Year()], pr)
+	}
+
+	stats := &PullStats{
+		Metrics:             pullRequestMetrics(prs),
+		Years:               make([]YearlyPullStats, 0, len(years)),
+		PullRequestsSampled: len(prs),
+	}
+	for i, year := range years {
+		stats.Totals.Commits += totals[i].Commits
+		stats.Totals.Issues += totals[i].Issues
+		stats.Totals.PullRequests += totals[i].PullRequests
+		stats.Totals.PullRequestReviews += totals[i].PullRequestReviews
+		stats.Years = append(stats.Years, YearlyPullStats{
+			Year:    year,
+			Totals:  totals[i],
+			Metrics: pullRequestMetrics(byYear[year]),
+		})
+	}
+	sort.Slice(stats.Years, func(i, j int) bool {
+		return stats.Years[i].Year < stats.Years[j].Year
+	})
+	return stats, nil
+}
+
+// pullRequestMetrics computes merge ratio, time to merge and time to first
+// review of prs.
+func pullRequestMetrics(prs []pullRequest) PullRequestMetrics {
+	var metrics PullRequestMetrics
+	var toMerge, toReview []float64
+
+	for _, pr := range prs {
+		metrics.Opened++
+		switch {
+		case !pr.MergedAt.IsZero():
+			metrics.Merged++
+			toMerge = append(toMerge, pr.MergedAt.Sub(pr.CreatedAt).Hours())
+		case !pr.ClosedAt.IsZero():
+			metrics.Closed++
+		default:
+			metrics.Open++
+		}
+		if !pr.FirstReview.IsZero() {
+			toReview = append(toReview, pr.FirstReview.Sub(pr.CreatedAt).Hours())
+		}
+	}
+
+	if resolved := metrics.Merged + metrics.Closed; resolved > 0 {
+		metrics.MergeRate = percentage(int64(metrics.Merged), int64(resolved))
+	}
+	if len(toMerge) > 0 {
+		var sum float64
+		for _, h := range toMerge {
+			sum += h
+		}
+		metrics.AvgTimeToMergeHours = roundHours(sum / float64(len(toMerge)))
+		metrics.MedianTimeToMergeHours = roundHours(median(toMerge))
+	}
+	if len(toReview) > 0 {
+		metrics.MedianFirstReviewHours = roundHours(median(toReview))
+	}
+	return metrics
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func roundHours(h float64) float64 {
+	return math.Round(h*10) / 10
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPullRequestMetrics(t *testing.T) {
+	created := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	prs := []pullRequest{
+		{CreatedAt: created, MergedAt: created.Add(2 * time.Hour), FirstReview: created.Add(time.Hour)},
+		{CreatedAt: created, MergedAt: created.Add(10 * time.Hour), FirstReview: created.Add(3 * time.Hour)},
+		{CreatedAt: created, MergedAt: created.Add(30 * time.Hour)},
+		{CreatedAt: created, ClosedAt: created.Add(5 * time.Hour)},
+		{CreatedAt: created},
+	}
+
+	metrics := pullRequestMetrics(prs)
+
+	if metrics.Opened != 5 || metrics.Merged != 3 || metrics.Closed != 1 || metrics.Open != 1 {
+		t.Errorf("unexpected counts: %+v", metrics)
+	}
+	if metrics.MergeRate != 75 {
+		t.Errorf("expected merge rate 75, got %v", metrics.MergeRate)
+	}
+	if metrics.MedianTimeToMergeHours != 10 {
+		t.Errorf("expected median time to merge 10h, got %v", metrics.MedianTimeToMergeHours)
+	}
+	if metrics.AvgTimeToMergeHours != 14 {
+		t.Errorf("expected average time to merge 14h, got %v", metrics.AvgTimeToMergeHours)
+	}
+	if metrics.MedianFirstReviewHours != 2 {
+		t.Errorf("expected median first review 2h, got %v", metrics.MedianFirstReviewHours)
+	}
+}
+
+func TestPullRequestMetrics_Empty(t *testing.T) {
+	metrics := pullRequestMetrics(nil)
+	if metrics != (PullRequestMetrics{}) {
+		t.Errorf("expected zero metrics, got %+v", metrics)
+	}
+}
+
+func TestClient_GetPullStats_BreaksDownByYear(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		var user map[string]any
+		switch {
+		case strings.Contains(body.Query, "contributionYears"):
+			user = map[string]any{"contributionsCollection": map[string]any{"contributionYears": []int{2025, 2024}}}
+		case strings.Contains(body.Query, "pullRequests("):
+			user = map[string]any{"pullRequests": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes": []map[string]any{
+					{
+						"createdAt": "2025-02-01T00:00:00Z",
+						"mergedAt":  "2025-02-01T04:00:00Z",
+						"reviews": map[string]any{"nodes": []map[string]any{
+							{"createdAt": "2025-02-01T00:30:00Z", "author": map[string]any{"login": "OctoCat"}},
+							{"createdAt": "2025-02-01T01:00:00Z", "author": map[string]any{"login": "reviewer"}},
+						}},
+					},
+					{"createdAt": "2024-06-01T00:00:00Z", "closedAt": "2024-06-02T00:00:00Z"},
+				},
+			}}
+		default:
+			reviews := 3
+			if strings.Contains(body.Query, "2024-01-01") {
+				reviews = 7
+			}
+			user = map[string]any{"contributionsCollection": map[string]any{
+				"totalPullRequestContributions":       1,
+				"totalPullRequestReviewContributions": reviews,
+			}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": user}})
+	}))
+	defer server.Close()
+
+	original := SetGraphQLURL(server.URL)
+	defer SetGraphQLURL(original)
+
+	stats, err := NewClient("ghp_pulls").GetPullStats(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Totals.PullRequestReviews != 10 || stats.Totals.PullRequests != 2 {
+		t.Errorf("unexpected lifetime totals: %+v", stats.Totals)
+	}
+	if len(stats.Years) != 2 || stats.Years[0].Year != 2024 {
+		t.Fatalf("expected years in chronological order, got %+v", stats.Years)
+	}
+	if stats.Years[0].Metrics.Closed != 1 || stats.Years[1].Metrics.Merged != 1 {
+		t.Errorf("expected PRs grouped by creation year, got %+v", stats.Years)
+	}
+	if stats.Metrics.MedianFirstReviewHours != 1 {
+		t.Errorf("expected the author's own review to be ignored, got %vh", stats.Metrics.MedianFirstReviewHours)
+	}
+	if stats.Metrics.MergeRate != 50 {
+		t.Errorf("expected merge rate 50, got %v", stats.Metrics.MergeRate)
+	}
+}
+
+func TestClient_GetPullRequests_PagesPastAuthorReplies(t *testing.T) {
+	var replies []map[string]any
+	for i := 0; i < 5; i++ {
+		replies = append(replies, map[string]any{"createdAt": "2025-02-01T00:10:00Z", "author": map[string]any{"login": "octocat"}})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		var data map[string]any
+		if strings.Contains(body.Query, "node(id:") {
+			if body.Variables["id"] != "PR_1" || body.Variables["cursor"] != "r5" {
+				t.Errorf("unexpected review page request: %v", body.Variables)
+			}
+			data = map[string]any{"node": map[string]any{"reviews": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes": []map[string]any{
+					{"createdAt": "2025-02-01T00:20:00Z", "author": map[string]any{"login": "octocat"}},
+					{"createdAt": "2025-02-01T03:00:00Z", "author": map[string]any{"login": "reviewer"}},
+				},
+			}}}
+		} else {
+			data = map[string]any{"user": map[string]any{"pullRequests": map[string]any{
+				"pageInfo": map[string]any{"hasNextPage": false},
+				"nodes": []map[string]any{{
+					"id":        "PR_1",
+					"createdAt": "2025-02-01T00:00:00Z",
+					"reviews": map[string]any{
+						"pageInfo": map[string]any{"hasNextPage": true, "endCursor": "r5"},
+						"nodes":    replies,
+					},
+				}},
+			}}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+	defer SetGraphQLURL(SetGraphQLURL(server.URL))
+
+	prs, err := NewClient("ghp_reviews").getPullRequests(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2025, 2, 1, 3, 0, 0, 0, time.UTC)
+	if len(prs) != 1 || !prs[0].FirstReview.Equal(want) {
+		t.Errorf("expected the first review by someone else at %v, got %+v", want, prs)
+	}
+}
@@ -0,0 +1,195 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rankHistoryRetention is how long country ranking snapshots are kept,
+// enough for weekly movement with room for missed days.
+const rankHistoryRetention = 30 * 24 * time.Hour
+
+// RankHistory keeps dated snapshots of country rankings, one per country and
+// UTC day. A later snapshot of the same day replaces the earlier one.
+type RankHistory interface {
+	// Record saves a country's logins in rank order as the snapshot of day.
+	Record(country string, day time.Time, logins []string) error
+	// Ranks returns the latest snapshot of country taken on or before day,
+	// as 1-based ranks by lower-cased login, and the day it was taken.
+	Ranks(country string, day time.Time) (map[string]int, time.Time, bool)
+}
+
+// rankDay truncates t to its UTC day.
+func rankDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func ranksOf(logins []string) map[string]int {
+	ranks := make(map[string]int, len(logins))
+	for i, login := range logins {
+		ranks[strings.ToLower(login)] = i + 1
+	}
+	return ranks
+}
+
+// MemoryRankHistory is a RankHistory that lives only as long as the process.
+type MemoryRankHistory struct {
+	mu        sync.RWMutex
+	countries map[string]map[time.Time]map[string]int
+}
+
+func NewMemoryRankHistory() *MemoryRankHistory {
+	return &MemoryRankHistory{countries: make(map[string]map[time.Time]map[string]int)}
+}
+
+func (m *MemoryRankHistory) Record(country string, day time.Time, logins []string) error {
+	day = rankDay(day)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	days := m.countries[country]
+	if days == nil {
+		days = make(map[time.Time]map[string]int)
+		m.countries[country] = days
+	}
+	days[day] = ranksOf(logins)
+	for d := range days {
+		if day.Sub(d) > rankHistoryRetention {
+			delete(days, d)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRankHistory) Ranks(country string, day time.Time) (map[string]int, time.Time, bool) {
+	day = rankDay(day)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var found time.Time
+	for d := range m.countries[country] {
+		if !d.After(day) && d.After(found) {
+			found = d
+		}
+	}
+	if found.IsZero() {
+		return nil, time.Time{}, false
+	}
+	return m.countries[country][found], found, true
+}
+
+// FileRankHistory stores snapshots as dir/<country>/<YYYY-MM-DD>.json, each
+// a JSON array of logins in rank order, so rankings survive restarts.
+type FileRankHistory struct {
+	dir string
+
+	mu sync.Mutex
+	// loaded caches decoded snapshots. Lookups only ever ask for a few
+	// reference days, so it is reset rather than evicted when it grows.
+	loaded map[string]map[string]int
+}
+
+func NewFileRankHistory(dir string) (*FileRankHistory, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create rank history directory: %w", err)
+	}
+	return &FileRankHistory{dir: dir, loaded: make(map[string]map[string]int)}, nil
+}
+
+func (f *FileRankHistory) Record(country string, day time.Time, logins []string) error {
+	if !safeCountryDir(country) {
+		return fmt.Errorf("invalid country %q", country)
+	}
+	countryDir := filepath.Join(f.dir, country)
+	if err := os.MkdirAll(countryDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create rank history directory: %w", err)
+	}
+	data, err := json.Marshal(logins)
+	if err != nil {
+		return err
+	}
+
+	day = rankDay(day)
+	path := filepath.Join(countryDir, day.Format("2006-01-02")+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write rank snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write rank snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	delete(f.loaded, path)
+	f.mu.Unlock()
+
+	for _, d := range f.days(country) {
+		if day.Sub(d) > rankHistoryRetention {
+			os.Remove(filepath.Join(countryDir, d.Format("2006-01-02")+".json"))
+		}
+	}
+	return nil
+}
+
+func (f *FileRankHistory) Ranks(country string, day time.Time) (map[string]int, time.Time, bool) {
+	if !safeCountryDir(country) {
+		return nil, time.Time{}, false
+	}
+	day = rankDay(day)
+	days := f.days(country)
+	i := sort.Search(len(days), func(i int) bool { return days[i].After(day) })
+	if i == 0 {
+		return nil, time.Time{}, false
+	}
+	found := days[i-1]
+	path := filepath.Join(f.dir, country, found.Format("2006-01-02")+".json")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if ranks, ok := f.loaded[path]; ok {
+		return ranks, found, true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var logins []string
+	if err := json.Unmarshal(data, &logins); err != nil {
+		return nil, time.Time{}, false
+	}
+	if len(f.loaded) >= 2*len(defaultCountries) {
+		f.loaded = make(map[string]map[string]int)
+	}
+	f.loaded[path] = ranksOf(logins)
+	return f.loaded[path], found, true
+}
+
+// safeCountryDir reports whether country can be used as a directory name
+// below the history directory.
+func safeCountryDir(country string) bool {
+	return country != "" && !strings.ContainsAny(country, `/\.`)
+}
+
+// days lists the days with a snapshot of country, oldest first.
+func (f *FileRankHistory) days(country string) []time.Time {
+	entries, err := os.ReadDir(filepath.Join(f.dir, country))
+	if err != nil {
+		return nil
+	}
+	var days []time.Time
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if d, err := time.Parse("2006-01-02", name); err == nil {
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
@@ -0,0 +1,127 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRankHistory_RanksOnOrBeforeDay(t *testing.T) {
+	file, err := NewFileRankHistory(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	for name, history := range map[string]RankHistory{"memory": NewMemoryRankHistory(), "file": file} {
+		t.Run(name, func(t *testing.T) {
+			history.Record("lithuania", day.AddDate(0, 0, -40), []string{"old"})
+			history.Record("lithuania", day.AddDate(0, 0, -7), []string{"alice", "Bob"})
+			history.Record("lithuania", day.AddDate(0, 0, -1), []string{"carol"})
+			history.Record("lithuania", day.AddDate(0, 0, -1).Add(time.Hour), []string{"bob", "alice"})
+
+			ranks, since, ok := history.Ranks("lithuania", day.AddDate(0, 0, -2))
+			if !ok || !since.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("expected the snapshot of 2025-03-03, got %v %v", since, ok)
+			}
+			if ranks["alice"] != 1 || ranks["bob"] != 2 {
+				t.Errorf("expected lower-cased ranks, got %v", ranks)
+			}
+
+			ranks, _, _ = history.Ranks("lithuania", day)
+			if ranks["bob"] != 1 || ranks["carol"] != 0 {
+				t.Errorf("expected the later snapshot of the day to win, got %v", ranks)
+			}
+			if _, _, ok := history.Ranks("lithuania", day.AddDate(0, 0, -35)); ok {
+				t.Error("expected snapshots past retention to be dropped")
+			}
+			if _, _, ok := history.Ranks("latvia", day); ok {
+				t.Error("expected no snapshot for another country")
+			}
+		})
+	}
+}
+
+func TestRankingService_GetCountryMovers(t *testing.T) {
+	users := []CountryUser{{Login: "climber"}, {Login: "steady"}, {Login: "newcomer"}, {Login: "faller"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(users)
+	}))
+	defer server.Close()
+
+	service := NewRankingService()
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
+		return http.Get(server.URL)
+	}
+	service.history.Record("lithuania", time.Now().AddDate(0, 0, -8), []string{"faller", "steady", "climber"})
+	service.history.Record("lithuania", time.Now().AddDate(0, 0, -1), []string{"faller", "climber", "steady"})
+
+	movers, err := service.GetCountryMovers("lithuania", 7, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(movers.Up) != 1 || movers.Up[0].Login != "climber" || movers.Up[0].Change != 2 {
+		t.Errorf("expected climber up 2 places, got %+v", movers.Up)
+	}
+	if len(movers.Down) != 1 || movers.Down[0].Login != "faller" || movers.Down[0].Change != -3 {
+		t.Errorf("expected faller down 3 places, got %+v", movers.Down)
+	}
+
+	ranking, _ := service.GetUserRanking("climber", "lithuania")
+	if ranking.DayChange == nil || ranking.DayChange.PreviousRank != 2 || ranking.DayChange.Change != 1 {
+		t.Errorf("expected climber up 1 place since yesterday, got %+v", ranking.DayChange)
+	}
+	if ranking.WeekChange == nil || ranking.WeekChange.Change != 2 {
+		t.Errorf("expected climber up 2 places since last week, got %+v", ranking.WeekChange)
+	}
+	ranking.SortBy(MetricFollowers)
+	if ranking.DayChange != nil || ranking.WeekChange != nil {
+		t.Errorf("expected no movement by followers, got %+v %+v", ranking.DayChange, ranking.WeekChange)
+	}
+	if ranking.Ranks[MetricPublic].DayChange == nil || ranking.Ranks[MetricFollowers].DayChange != nil {
+		t.Errorf("expected movement under the public ranks only, got %+v", ranking.Ranks)
+	}
+	if ranking, _ := service.GetUserRanking("newcomer", "lithuania"); ranking.DayChange != nil {
+		t.Errorf("expected no movement for a new user, got %+v", ranking.DayChange)
+	}
+}
+
+// lockCheckingHistory fails lookups made while the ranking service's lock
+// is held.
+type lockCheckingHistory struct {
+	*MemoryRankHistory
+	service *RankingService
+	t       *testing.T
+}
+
+func (h lockCheckingHistory) Ranks(country string, day time.Time) (map[string]int, time.Time, bool) {
+	if !h.service.mu.TryLock() {
+		h.t.Error("expected rank history to be read without holding the ranking lock")
+	} else {
+		h.service.mu.Unlock()
+	}
+	return h.MemoryRankHistory.Ranks(country, day)
+}
+
+func TestRankingService_RankMovementOutsideLock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]CountryUser{{Login: "climber"}, {Login: "steady"}})
+	}))
+	defer server.Close()
+
+	service := NewRankingService()
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
+		return http.Get(server.URL)
+	}
+	service.SetRankHistory(lockCheckingHistory{NewMemoryRankHistory(), service, t})
+	service.history.Record("lithuania", time.Now().AddDate(0, 0, -1), []string{"steady", "climber"})
+
+	if ranking, _ := service.GetUserRanking("climber", "lithuania"); ranking.DayChange == nil || ranking.DayChange.Change != 1 {
+		t.Errorf("expected climber up 1 place, got %+v", ranking.DayChange)
+	}
+	if ranking, _ := service.FindUserRanking("steady"); ranking == nil || ranking.DayChange == nil || ranking.DayChange.Change != -1 {
+		t.Errorf("expected steady down 1 place, got %+v", ranking)
+	}
+}
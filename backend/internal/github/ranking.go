@@ -1,8 +1,7 @@
 package github
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"log"
 	"net/http"
 	"sort"
@@ -12,10 +11,11 @@ import (
 )
 
 const (
-	rankingBaseURL      = "https://raw.githubusercontent.com/gayanvoice/top-github-users/main/cache"
 	rankingTTL          = 6 * time.Hour
-	countriesListURL    = "https://api.github.com/repos/gayanvoice/top-github-users/contents/cache"
 	countriesRefreshTTL = 24 * time.Hour
+	// rankingFetchTimeout bounds fetching one country, which for the search
+	// source takes several rate-limited API calls.
+	rankingFetchTimeout = 2 * time.Minute
 )
 
 var defaultCountries = []string{
@@ -41,20 +41,28 @@ var defaultCountries = []string{
 }
 
 type GlobalUser struct {
-	Login               string `json:"login"`
-	Country             string `json:"country"`
-	PublicContributions int    `json:"publicContributions"`
+	Login                string `json:"login"`
+	Country              string `json:"country"`
+	PublicContributions  int    `json:"publicContributions"`
+	PrivateContributions int    `json:"privateContributions"`
+	Followers            int    `json:"followers"`
 }
 
 type RankingService struct {
-	mu                 sync.RWMutex
-	cache              map[string]*CountryRanking
+	mu    sync.RWMutex
+	cache map[string]*CountryRanking
+	// countryRanks indexes each cached ranking by every metric.
+	countryRanks map[string]*metricIndex
+	// globalIndex holds every cached user by public contributions, and
+	// globalRanks indexes it by every metric.
 	globalIndex        []GlobalUser
-	globalMap          map[string]int
+	globalRanks        *metricIndex
 	availableCountries []string
 	countriesUpdatedAt time.Time
-	httpGet            func(url string) (*http.Response, error)
-	token              string
+	httpDo             func(req *http.Request) (*http.Response, error)
+	source             RankingSource
+	history            RankHistory
+	preload            RankingLoadStatus
 }
 
 func NewRankingService() *RankingService {
@@ -64,16 +72,38 @@ func NewRankingService() *RankingService {
 func NewRankingServiceWithToken(token string) *RankingService {
 	rs := &RankingService{
 		cache:              make(map[string]*CountryRanking),
+		countryRanks:       make(map[string]*metricIndex),
 		globalIndex:        []GlobalUser{},
-		globalMap:          make(map[string]int),
+		globalRanks:        newMetricIndex(nil),
 		availableCountries: []string{},
-		httpGet:            http.Get,
-		token:              token,
+		httpDo:             rankingHTTPClient.Do,
+		history:            NewMemoryRankHistory(),
 	}
+	// The remote source reads httpDo on each call so it can be replaced.
+	rs.source = &remoteSource{token: token, httpDo: func(req *http.Request) (*http.Response, error) {
+		return rs.httpDo(req)
+	}}
 	go rs.refreshCountriesList()
 	return rs
 }
 
+// SetSource replaces where rankings come from and reloads the country list.
+// Rankings already cached are kept until they expire.
+func (r *RankingService) SetSource(source RankingSource) {
+	r.mu.Lock()
+	r.source = source
+	r.availableCountries = []string{}
+	r.countriesUpdatedAt = time.Time{}
+	r.mu.Unlock()
+	go r.refreshCountriesList()
+}
+
+func (r *RankingService) currentSource() RankingSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.source
+}
+
 func (r *RankingService) GetCountryRanking(country string) (*CountryRanking, error) {
 	normalizedCountry := normalizeCountryName(country)
 
@@ -85,7 +115,9 @@ func (r *RankingService) GetCountryRanking(country string) (*CountryRanking, err
 		return cached, nil
 	}
 
-	ranking, err := r.fetchCountryRanking(normalizedCountry)
+	ctx, cancel := context.WithTimeout(context.Background(), rankingFetchTimeout)
+	defer cancel()
+	ranking, err := r.fetchCountryRanking(ctx, normalizedCountry)
 	if err != nil {
 		if cached != nil {
 			return cached, nil
@@ -93,56 +125,163 @@ func (r *RankingService) GetCountryRanking(country string) (*CountryRanking, err
 		return nil, err
 	}
 
+	r.storeRanking(ranking, true)
+	return ranking, nil
+}
+
+// GetCountryRankingBy returns a country's ranking ordered by metric.
+func (r *RankingService) GetCountryRankingBy(country string, metric RankingMetric) (*CountryRanking, error) {
+	ranking, err := r.GetCountryRanking(country)
+	if err != nil || metric == MetricPublic {
+		return ranking, err
+	}
+
+	r.mu.RLock()
+	idx := r.countryRanks[ranking.Country]
+	r.mu.RUnlock()
+	if idx == nil {
+		idx = indexCountryUsers(ranking.Users)
+	}
+
+	sorted := *ranking
+	sorted.Users = make([]CountryUser, len(ranking.Users))
+	for rank, i := range idx.order[metric] {
+		sorted.Users[rank] = ranking.Users[i]
+	}
+	return &sorted, nil
+}
+
+// storeRanking caches a fetched ranking, ordered by public contributions
+// and indexed by every metric, and records it in the history. The global
+// index is rebuilt only when rebuild is set, so loading many countries can
+// rebuild it once at the end.
+func (r *RankingService) storeRanking(ranking *CountryRanking, rebuild bool) {
+	sort.SliceStable(ranking.Users, func(i, j int) bool {
+		return ranking.Users[i].PublicContributions > ranking.Users[j].PublicContributions
+	})
+	idx := indexCountryUsers(ranking.Users)
+
 	r.mu.Lock()
-	r.cache[normalizedCountry] = ranking
-	r.rebuildGlobalIndex()
+	r.cache[ranking.Country] = ranking
+	r.countryRanks[ranking.Country] = idx
+	if rebuild {
+		r.rebuildGlobalIndex()
+	}
 	r.mu.Unlock()
 
-	return ranking, nil
+	logins := make([]string, len(ranking.Users))
+	for i, user := range ranking.Users {
+		logins[i] = user.Login
+	}
+	if err := r.history.Record(ranking.Country, ranking.UpdatedAt, logins); err != nil {
+		log.Printf("Failed to record %s ranking history: %v", ranking.Country, err)
+	}
+}
+
+// SetRankHistory replaces where ranking snapshots are kept, in memory by
+// default. Call it before serving requests.
+func (r *RankingService) SetRankHistory(history RankHistory) {
+	r.history = history
+}
+
+// GetCountryMovers compares a country's ranking with its snapshot from days
+// ago and returns up to limit users who moved furthest in each direction.
+func (r *RankingService) GetCountryMovers(country string, days, limit int) (*CountryMovers, error) {
+	ranking, err := r.GetCountryRanking(country)
+	if err != nil {
+		return nil, err
+	}
+
+	movers := &CountryMovers{Country: ranking.Country, Up: []RankMover{}, Down: []RankMover{}}
+	previous, since, ok := r.history.Ranks(ranking.Country, time.Now().AddDate(0, 0, -days))
+	if !ok {
+		return movers, nil
+	}
+	movers.Since = since.Format("2006-01-02")
+
+	for i, user := range ranking.Users {
+		prev, ok := previous[strings.ToLower(user.Login)]
+		if !ok || prev == i+1 {
+			continue
+		}
+		mover := RankMover{
+			Login:        user.Login,
+			Name:         user.Name,
+			AvatarURL:    user.AvatarURL,
+			Rank:         i + 1,
+			PreviousRank: prev,
+			Change:       prev - (i + 1),
+		}
+		if mover.Change > 0 {
+			movers.Up = append(movers.Up, mover)
+		} else {
+			movers.Down = append(movers.Down, mover)
+		}
+	}
+	sort.SliceStable(movers.Up, func(i, j int) bool { return movers.Up[i].Change > movers.Up[j].Change })
+	sort.SliceStable(movers.Down, func(i, j int) bool { return movers.Down[i].Change < movers.Down[j].Change })
+	if len(movers.Up) > limit {
+		movers.Up = movers.Up[:limit]
+	}
+	if len(movers.Down) > limit {
+		movers.Down = movers.Down[:limit]
+	}
+	return movers, nil
+}
+
+// rankMovement compares a user's country rank with the snapshot from days
+// ago, or returns nil when the user is not in one.
+func (r *RankingService) rankMovement(country, login string, rank, days int) *RankMovement {
+	previous, since, ok := r.history.Ranks(country, time.Now().AddDate(0, 0, -days))
+	if !ok {
+		return nil
+	}
+	prev, ok := previous[strings.ToLower(login)]
+	if !ok {
+		return nil
+	}
+	return &RankMovement{
+		Since:        since.Format("2006-01-02"),
+		PreviousRank: prev,
+		Change:       prev - rank,
+	}
 }
 
+// rebuildGlobalIndex merges the cached rankings. Countries are visited in
+// name order so ties rank the same way on every rebuild. Callers must hold
+// r.mu.
 func (r *RankingService) rebuildGlobalIndex() {
+	countries := make([]string, 0, len(r.cache))
+	for country := range r.cache {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
 	var allUsers []GlobalUser
-	for country, ranking := range r.cache {
-		for _, user := range ranking.Users {
+	for _, country := range countries {
+		for _, user := range r.cache[country].Users {
 			allUsers = append(allUsers, GlobalUser{
-				Login:               user.Login,
-				Country:             country,
-				PublicContributions: user.PublicContributions,
+				Login:                user.Login,
+				Country:              country,
+				PublicContributions:  user.PublicContributions,
+				PrivateContributions: user.PrivateContributions,
+				Followers:            user.Followers,
 			})
 		}
 	}
 
-	sort.Slice(allUsers, func(i, j int) bool {
+	sort.SliceStable(allUsers, func(i, j int) bool {
 		return allUsers[i].PublicContributions > allUsers[j].PublicContributions
 	})
 
 	r.globalIndex = allUsers
-	r.globalMap = make(map[string]int, len(allUsers))
-	for i, user := range allUsers {
-		r.globalMap[strings.ToLower(user.Login)] = i
-	}
+	r.globalRanks = indexGlobalUsers(allUsers)
 }
 
-func (r *RankingService) fetchCountryRanking(country string) (*CountryRanking, error) {
-	url := fmt.Sprintf("%s/%s.json", rankingBaseURL, country)
-
-	resp, err := r.httpGet(url)
+func (r *RankingService) fetchCountryRanking(ctx context.Context, country string) (*CountryRanking, error) {
+	users, err := r.currentSource().CountryUsers(ctx, country)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch ranking data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("country not found: %s", country)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var users []CountryUser
-	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
-		return nil, fmt.Errorf("failed to decode ranking data: %w", err)
+		return nil, err
 	}
 
 	return &CountryRanking{
@@ -158,44 +297,76 @@ func (r *RankingService) GetUserRanking(username string, country string) (*UserR
 		return nil, err
 	}
 
-	return r.findUserInRanking(username, ranking), nil
+	r.mu.RLock()
+	result := r.findUserInRanking(username, ranking)
+	r.mu.RUnlock()
+
+	r.addRankMovement(result)
+	return result, nil
 }
 
 func (r *RankingService) FindUserRanking(username string) (*UserRanking, error) {
+	var result *UserRanking
 	r.mu.RLock()
 	for _, ranking := range r.cache {
-		if result := r.findUserInRanking(username, ranking); result != nil {
-			r.mu.RUnlock()
-			return result, nil
+		if result = r.findUserInRanking(username, ranking); result != nil {
+			break
 		}
 	}
 	r.mu.RUnlock()
 
-	return nil, nil
+	r.addRankMovement(result)
+	return result, nil
+}
+
+// addRankMovement fills in how a found user's country rank by public
+// contributions changed, the order rank history is recorded in. The rank
+// history may read files, so callers must not hold r.mu.
+func (r *RankingService) addRankMovement(u *UserRanking) {
+	if u == nil {
+		return
+	}
+	public := u.Ranks[MetricPublic]
+	public.DayChange = r.rankMovement(u.Country, u.Username, public.CountryRank, 1)
+	public.WeekChange = r.rankMovement(u.Country, u.Username, public.CountryRank, 7)
+	u.Ranks[MetricPublic] = public
+	u.SortBy(u.Sort)
 }
 
+// findUserInRanking ranks a user by every metric, reporting public
+// contributions ranks at the top level. Rank movement is left to
+// addRankMovement. Callers must hold r.mu for reading.
 func (r *RankingService) findUserInRanking(username string, ranking *CountryRanking) *UserRanking {
 	lowerUsername := strings.ToLower(username)
-	for i, user := range ranking.Users {
-		if strings.ToLower(user.Login) == lowerUsername {
-			globalRank := 0
-			globalTotal := len(r.globalIndex)
-			if idx, ok := r.globalMap[lowerUsername]; ok {
-				globalRank = idx + 1
-			}
+	for _, user := range ranking.Users {
+		if strings.ToLower(user.Login) != lowerUsername {
+			continue
+		}
 
-			return &UserRanking{
-				Username:             user.Login,
-				Country:              ranking.Country,
-				CountryRank:          i + 1,
-				CountryTotal:         len(ranking.Users),
-				GlobalRank:           globalRank,
-				GlobalTotal:          globalTotal,
-				PublicContributions:  user.PublicContributions,
-				PrivateContributions: user.PrivateContributions,
-				Followers:            user.Followers,
+		idx := r.countryRanks[ranking.Country]
+		if idx == nil {
+			idx = indexCountryUsers(ranking.Users)
+		}
+		ranks := make(map[RankingMetric]MetricRank, len(RankingMetrics))
+		for _, m := range RankingMetrics {
+			ranks[m] = MetricRank{
+				CountryRank: idx.ranks[m][lowerUsername],
+				GlobalRank:  r.globalRanks.ranks[m][lowerUsername],
 			}
 		}
+		return &UserRanking{
+			Username:             user.Login,
+			Country:              ranking.Country,
+			Sort:                 MetricPublic,
+			CountryRank:          ranks[MetricPublic].CountryRank,
+			CountryTotal:         len(ranking.Users),
+			GlobalRank:           ranks[MetricPublic].GlobalRank,
+			GlobalTotal:          len(r.globalIndex),
+			Ranks:                ranks,
+			PublicContributions:  user.PublicContributions,
+			PrivateContributions: user.PrivateContributions,
+			Followers:            user.Followers,
+		}
 	}
 	return nil
 }
@@ -227,6 +398,12 @@ func (r *RankingService) GetAvailableCountries() []string {
 }
 
 func (r *RankingService) GetGlobalRanking(limit int) []GlobalUser {
+	return r.GetGlobalRankingBy(MetricPublic, limit)
+}
+
+// GetGlobalRankingBy returns the top limit users of all cached countries
+// ordered by metric.
+func (r *RankingService) GetGlobalRankingBy(metric RankingMetric, limit int) []GlobalUser {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -235,54 +412,31 @@ func (r *RankingService) GetGlobalRanking(limit int) []GlobalUser {
 	}
 
 	result := make([]GlobalUser, limit)
-	copy(result, r.globalIndex[:limit])
+	for rank, i := range r.globalRanks.order[metric][:limit] {
+		result[rank] = r.globalIndex[i]
+	}
 	return result
 }
 
 func (r *RankingService) refreshCountriesList() {
-	req, err := http.NewRequest("GET", countriesListURL, nil)
-	if err != nil {
-		log.Printf("Failed to create request for countries list: %v", err)
-		return
-	}
+	source := r.currentSource()
+	ctx, cancel := context.WithTimeout(context.Background(), rankingFetchTimeout)
+	defer cancel()
 
-	if r.token != "" {
-		req.Header.Set("Authorization", "token "+r.token)
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	countries, err := source.Countries(ctx)
 	if err != nil {
 		log.Printf("Failed to fetch countries list: %v", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Failed to fetch countries list: status %d", resp.StatusCode)
-		return
-	}
-
-	var contents []struct {
-		Name string `json:"name"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&contents); err != nil {
-		log.Printf("Failed to decode countries list: %v", err)
-		return
-	}
-
-	countries := make([]string, 0, len(contents))
-	for _, c := range contents {
-		if strings.HasSuffix(c.Name, ".json") {
-			countries = append(countries, strings.TrimSuffix(c.Name, ".json"))
-		}
-	}
 	sort.Strings(countries)
 
 	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.source != source {
+		return
+	}
 	r.availableCountries = countries
 	r.countriesUpdatedAt = time.Now()
-	r.mu.Unlock()
 
 	log.Printf("Refreshed countries list: %d countries available", len(countries))
 }
@@ -57,7 +57,7 @@ func TestRankingService_GetCountryRanking(t *testing.T) {
 	defer server.Close()
 
 	service := NewRankingService()
-	service.httpGet = func(url string) (*http.Response, error) {
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
 		return http.Get(server.URL + "/test_country.json")
 	}
 
@@ -84,7 +84,7 @@ func TestRankingService_GetCountryRanking_NotFound(t *testing.T) {
 	defer server.Close()
 
 	service := NewRankingService()
-	service.httpGet = func(url string) (*http.Response, error) {
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
 		return http.Get(server.URL + "/nonexistent.json")
 	}
 
@@ -113,8 +113,8 @@ func TestRankingService_GetCountryRanking_CachesResult(t *testing.T) {
 	defer server.Close()
 
 	service := NewRankingService()
-	service.httpGet = func(url string) (*http.Response, error) {
-		if strings.Contains(url, "cached_country") {
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "cached_country") {
 			return http.Get(server.URL + "/cached_country.json")
 		}
 		return http.Get(server.URL + "/countries")
@@ -183,7 +183,7 @@ func TestRankingService_GetUserRanking(t *testing.T) {
 	defer server.Close()
 
 	service := NewRankingService()
-	service.httpGet = func(url string) (*http.Response, error) {
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
 		return http.Get(server.URL + "/test.json")
 	}
 
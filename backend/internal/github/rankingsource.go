@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	rankingBaseURL   = "https://raw.githubusercontent.com/gayanvoice/top-github-users/main/cache"
+	countriesListURL = "https://api.github.com/repos/gayanvoice/top-github-users/contents/cache"
+)
+
+// rankingHTTPClient downloads country rankings, which can be several
+// megabytes, so it allows more time than the countries list.
+var rankingHTTPClient = &http.Client{Timeout: time.Minute}
+
+// RankingSource supplies country rankings. Countries are identified by
+// normalized names such as "united_states".
+type RankingSource interface {
+	// Countries lists the countries the source has rankings for.
+	Countries(ctx context.Context) ([]string, error)
+	// CountryUsers returns a country's users in rank order. Unknown
+	// countries yield ErrNotFound.
+	CountryUsers(ctx context.Context, country string) ([]CountryUser, error)
+}
+
+var (
+	_ RankingSource = (*remoteSource)(nil)
+	_ RankingSource = (*LocalSource)(nil)
+	_ RankingSource = (*SearchSource)(nil)
+)
+
+// remoteSource reads the JSON cache of the top-github-users project.
+type remoteSource struct {
+	token  string
+	httpDo func(req *http.Request) (*http.Response, error)
+}
+
+func (s *remoteSource) Countries(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", countriesListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "token "+s.token)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var contents []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&contents); err != nil {
+		return nil, fmt.Errorf("failed to decode countries list: %w", err)
+	}
+
+	countries := make([]string, 0, len(contents))
+	for _, c := range contents {
+		if strings.HasSuffix(c.Name, ".json") {
+			countries = append(countries, strings.TrimSuffix(c.Name, ".json"))
+		}
+	}
+	return countries, nil
+}
+
+func (s *remoteSource) CountryUsers(ctx context.Context, country string) ([]CountryUser, error) {
+	url := fmt.Sprintf("%s/%s.json", rankingBaseURL, country)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpDo(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ranking data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("country %s: %w", country, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var users []CountryUser
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("failed to decode ranking data: %w", err)
+	}
+	return users, nil
+}
+
+// LocalSource reads rankings from <country>.json files in a directory, in
+// the same format as the remote cache. It suits offline development and
+// tests, and can serve rankings produced elsewhere.
+type LocalSource struct {
+	dir string
+}
+
+func NewLocalSource(dir string) *LocalSource {
+	return &LocalSource{dir: dir}
+}
+
+func (s *LocalSource) Countries(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ranking directory: %w", err)
+	}
+	var countries []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			countries = append(countries, name)
+		}
+	}
+	sort.Strings(countries)
+	return countries, nil
+}
+
+func (s *LocalSource) CountryUsers(ctx context.Context, country string) ([]CountryUser, error) {
+	if !safeCountryDir(country) {
+		return nil, fmt.Errorf("country %s: %w", country, ErrNotFound)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, country+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("country %s: %w", country, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ranking data: %w", err)
+	}
+
+	var users []CountryUser
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode ranking data: %w", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,203 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLocalSource(t *testing.T) {
+	dir := t.TempDir()
+	data, _ := json.Marshal([]CountryUser{{Login: "jonas"}, {Login: "ona"}})
+	os.WriteFile(filepath.Join(dir, "lithuania.json"), data, 0o600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600)
+	source := NewLocalSource(dir)
+
+	countries, err := source.Countries(context.Background())
+	if err != nil || len(countries) != 1 || countries[0] != "lithuania" {
+		t.Errorf("expected [lithuania], got %v (%v)", countries, err)
+	}
+	users, err := source.CountryUsers(context.Background(), "lithuania")
+	if err != nil || len(users) != 2 || users[0].Login != "jonas" {
+		t.Errorf("expected the file's users, got %v (%v)", users, err)
+	}
+	for _, country := range []string{"latvia", "../lithuania"} {
+		if _, err := source.CountryUsers(context.Background(), country); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for %q, got %v", country, err)
+		}
+	}
+}
+
+func TestRankingService_SetSource(t *testing.T) {
+	dir := t.TempDir()
+	data, _ := json.Marshal([]CountryUser{{Login: "jonas"}})
+	os.WriteFile(filepath.Join(dir, "lithuania.json"), data, 0o600)
+
+	service := NewRankingService()
+	service.SetSource(NewLocalSource(dir))
+	ranking, err := service.GetCountryRanking("Lithuania")
+	if err != nil || len(ranking.Users) != 1 {
+		t.Fatalf("expected the local ranking, got %v (%v)", ranking, err)
+	}
+}
+
+func TestSearchSource_RanksByPublicContributions(t *testing.T) {
+	var searchQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/users":
+			searchQuery = r.URL.Query().Get("q")
+			json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{{"login": "famous"}, {"login": "busy"}},
+			})
+		case "/graphql":
+			var body struct {
+				Variables map[string]string `json:"variables"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Variables["l0"] != "famous" || body.Variables["l1"] != "busy" {
+				t.Errorf("unexpected variables %v", body.Variables)
+			}
+			profile := func(login string, followers, total, private int) map[string]any {
+				return map[string]any{
+					"login":     login,
+					"followers": map[string]any{"totalCount": followers},
+					"contributionsCollection": map[string]any{
+						"contributionCalendar":         map[string]any{"totalContributions": total},
+						"restrictedContributionsCount": private,
+					},
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"u0": profile("famous", 5000, 400, 300),
+				"u1": profile("busy", 10, 900, 0),
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+	defer SetGraphQLURL(SetGraphQLURL(server.URL + "/graphql"))
+
+	users, err := NewSearchSource("token").CountryUsers(context.Background(), "south_korea")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(searchQuery, `location:"south korea"`) {
+		t.Errorf("expected a location search, got %q", searchQuery)
+	}
+	if len(users) != 2 || users[0].Login != "busy" || users[1].PublicContributions != 100 || users[1].PrivateContributions != 300 {
+		t.Errorf("expected users ranked by public contributions, got %+v", users)
+	}
+}
+
+func TestSearchSource_SkipsOnlyFailedUsers(t *testing.T) {
+	var failQuery bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/users":
+			json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{{"login": "renamed"}, {"login": "active"}},
+			})
+		case "/graphql":
+			if failQuery {
+				json.NewEncoder(w).Encode(map[string]any{
+					"data":   nil,
+					"errors": []map[string]any{{"type": "RATE_LIMITED", "message": "slow down"}},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"u0": nil, "u1": map[string]any{"login": "active"}},
+				"errors": []map[string]any{
+					{"type": "NOT_FOUND", "path": []string{"u0"}, "message": "Could not resolve to a User"},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+	defer SetGraphQLURL(SetGraphQLURL(server.URL + "/graphql"))
+
+	source := NewSearchSource("token")
+	users, err := source.CountryUsers(context.Background(), "lithuania")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0].Login != "active" {
+		t.Errorf("expected only the failed user skipped, got %+v", users)
+	}
+
+	failQuery = true
+	if _, err := source.CountryUsers(context.Background(), "lithuania"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected an error for the whole query to fail the country, got %v", err)
+	}
+}
+
+func TestRemoteSource_CountryUsersUsesContext(t *testing.T) {
+	source := &remoteSource{httpDo: func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := source.CountryUsers(ctx, "lithuania"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the request to carry the cancelled context, got %v", err)
+	}
+}
+
+func TestSearchSource_PacesSearchesAndKeepsGraphQLReserve(t *testing.T) {
+	var searches, queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			queries.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{}})
+			return
+		}
+		searches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{"login": "jonas"}}})
+	}))
+	defer server.Close()
+	defer SetAPIURL(SetAPIURL(server.URL))
+	defer SetGraphQLURL(SetGraphQLURL(server.URL + "/graphql"))
+
+	exhausted := func(source *SearchSource, resource string, remaining int, reset time.Duration) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+		rateLimits.update(tokenIdentity(source.client.token), resource, header)
+	}
+
+	paced := NewSearchSource("ghp_paced")
+	exhausted(paced, ResourceSearch, 0, 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := paced.CountryUsers(ctx, "lithuania"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the search to wait for the budget to reset, got %v", err)
+	}
+
+	reserved := NewSearchSource("ghp_reserved")
+	exhausted(reserved, ResourceGraphQL, fanOutReserve, time.Hour)
+	var limitErr *RateLimitError
+	if _, err := reserved.CountryUsers(context.Background(), "lithuania"); !errors.As(err, &limitErr) {
+		t.Errorf("expected profile batches to respect the GraphQL reserve, got %v", err)
+	}
+	if searches.Load() != 1 || queries.Load() != 0 {
+		t.Errorf("expected 1 search and no profile queries, got %d and %d", searches.Load(), queries.Load())
+	}
+	if workers := paced.preloadWorkers(); workers != 1 {
+		t.Errorf("expected search rankings preloaded one country at a time, got %d workers", workers)
+	}
+}
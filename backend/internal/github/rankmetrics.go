@@ -0,0 +1,113 @@
+package github
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// RankingMetric is what rankings are ordered by.
+type RankingMetric string
+
+const (
+	MetricPublic    RankingMetric = "public"
+	MetricTotal     RankingMetric = "total"
+	MetricFollowers RankingMetric = "followers"
+)
+
+// RankingMetrics lists every metric rankings are indexed by.
+var RankingMetrics = []RankingMetric{MetricPublic, MetricTotal, MetricFollowers}
+
+// ParseRankingMetric reads a sort parameter, public contributions when
+// empty.
+func ParseRankingMetric(s string) (RankingMetric, error) {
+	if s == "" {
+		return MetricPublic, nil
+	}
+	for _, m := range RankingMetrics {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", errors.New("sort must be public, total or followers")
+}
+
+// SortBy reports a user's ranks and rank movement by metric at the top
+// level.
+func (u *UserRanking) SortBy(metric RankingMetric) {
+	rank := u.Ranks[metric]
+	u.Sort = metric
+	u.CountryRank = rank.CountryRank
+	u.GlobalRank = rank.GlobalRank
+	u.DayChange = rank.DayChange
+	u.WeekChange = rank.WeekChange
+}
+
+// rankedUser is what ranking by any metric needs of a user.
+type rankedUser struct {
+	login                      string
+	public, private, followers int
+}
+
+func (m RankingMetric) value(u rankedUser) int {
+	switch m {
+	case MetricTotal:
+		return u.public + u.private
+	case MetricFollowers:
+		return u.followers
+	default:
+		return u.public
+	}
+}
+
+// metricIndex is a list of users ordered by every metric. Ties keep the
+// list's order.
+type metricIndex struct {
+	// order holds positions in the list, best first.
+	order map[RankingMetric][]int
+	// ranks maps lower-cased logins to 1-based ranks.
+	ranks map[RankingMetric]map[string]int
+}
+
+func newMetricIndex(users []rankedUser) *metricIndex {
+	idx := &metricIndex{
+		order: make(map[RankingMetric][]int, len(RankingMetrics)),
+		ranks: make(map[RankingMetric]map[string]int, len(RankingMetrics)),
+	}
+	for _, m := range RankingMetrics {
+		order := make([]int, len(users))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return m.value(users[order[i]]) > m.value(users[order[j]])
+		})
+
+		ranks := make(map[string]int, len(users))
+		for rank, i := range order {
+			login := strings.ToLower(users[i].login)
+			if _, ok := ranks[login]; !ok {
+				ranks[login] = rank + 1
+			}
+		}
+		idx.order[m] = order
+		idx.ranks[m] = ranks
+	}
+	return idx
+}
+
+func indexCountryUsers(users []CountryUser) *metricIndex {
+	ranked := make([]rankedUser, len(users))
+	for i, u := range users {
+		ranked[i] = rankedUser{u.Login, u.PublicContributions, u.PrivateContributions, u.Followers}
+	}
+	return newMetricIndex(ranked)
+}
+
+func indexGlobalUsers(users []GlobalUser) *metricIndex {
+	ranked := make([]rankedUser, len(users))
+	for i, u := range users {
+		ranked[i] = rankedUser{u.Login, u.PublicContributions, u.PrivateContributions, u.Followers}
+	}
+	return newMetricIndex(ranked)
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRankingMetric(t *testing.T) {
+	for input, want := range map[string]RankingMetric{"": MetricPublic, "total": MetricTotal, "followers": MetricFollowers} {
+		if got, err := ParseRankingMetric(input); err != nil || got != want {
+			t.Errorf("ParseRankingMetric(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseRankingMetric("stars"); err == nil {
+		t.Error("expected an error for an unknown metric")
+	}
+}
+
+func TestRankingService_RanksByMetric(t *testing.T) {
+	countries := map[string][]CountryUser{
+		"lithuania": {
+			{Login: "quiet", PublicContributions: 100, PrivateContributions: 900, Followers: 5},
+			{Login: "public", PublicContributions: 500, PrivateContributions: 0, Followers: 50},
+		},
+		"latvia": {
+			{Login: "famous", PublicContributions: 300, PrivateContributions: 0, Followers: 9000},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(countries[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json")])
+	}))
+	defer server.Close()
+
+	service := NewRankingService()
+	service.httpDo = func(req *http.Request) (*http.Response, error) {
+		return http.Get(server.URL + req.URL.Path[strings.LastIndex(req.URL.Path, "/"):])
+	}
+
+	ranking, err := service.GetCountryRanking("lithuania")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ranking.Users[0].Login != "public" {
+		t.Errorf("expected the ranking ordered by public contributions, got %s first", ranking.Users[0].Login)
+	}
+	byTotal, _ := service.GetCountryRankingBy("lithuania", MetricTotal)
+	if byTotal.Users[0].Login != "quiet" {
+		t.Errorf("expected quiet first by total contributions, got %s", byTotal.Users[0].Login)
+	}
+	service.GetCountryRanking("latvia")
+
+	global := service.GetGlobalRankingBy(MetricFollowers, 2)
+	if len(global) != 2 || global[0].Login != "famous" || global[1].Login != "public" {
+		t.Errorf("expected famous then public by followers, got %+v", global)
+	}
+
+	user, _ := service.GetUserRanking("quiet", "lithuania")
+	want := map[RankingMetric]MetricRank{
+		MetricPublic:    {CountryRank: 2, GlobalRank: 3},
+		MetricTotal:     {CountryRank: 1, GlobalRank: 1},
+		MetricFollowers: {CountryRank: 2, GlobalRank: 3},
+	}
+	for m, rank := range want {
+		if user.Ranks[m] != rank {
+			t.Errorf("expected %s rank %+v, got %+v", m, rank, user.Ranks[m])
+		}
+	}
+	user.SortBy(MetricTotal)
+	if user.Sort != MetricTotal || user.CountryRank != 1 || user.GlobalRank != 1 {
+		t.Errorf("expected total ranks at the top level, got %+v", user)
+	}
+}
@@ -0,0 +1,211 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit resources as reported by GitHub in X-RateLimit-Resource.
+const (
+	ResourceCore    = "core"
+	ResourceGraphQL = "graphql"
+	ResourceSearch  = "search"
+)
+
+const (
+	// maxRateLimitWait is how long a request may block waiting for an
+	// exhausted budget to reset before giving up.
+	maxRateLimitWait = 10 * time.Second
+	// maxSearchWait lets background search crawls wait out a whole window
+	// of the search budget, which allows 30 calls a minute, instead of
+	// failing.
+	maxSearchWait = time.Minute + 5*time.Second
+	// maxRetryAfter caps the Retry-After delay honoured on secondary limits.
+	maxRetryAfter = 60 * time.Second
+	// secondaryBackoff is the initial delay when a secondary limit is hit
+	// without a Retry-After header. It doubles with every retry.
+	secondaryBackoff = 5 * time.Second
+	maxRetries       = 2
+	// fanOutReserve is kept aside for interactive requests when deciding
+	// whether a large fan-out may start.
+	fanOutReserve = 100
+)
+
+// RateLimit is the last known state of one GitHub rate limit budget.
+type RateLimit struct {
+	Resource  string    `json:"resource"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	Reset     time.Time `json:"reset"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// rateTracker records budgets per token identity and resource. It is shared
+// by all clients because handlers create a fresh Client per request.
+type rateTracker struct {
+	mu     sync.Mutex
+	limits map[string]map[string]RateLimit
+}
+
+var rateLimits = &rateTracker{limits: make(map[string]map[string]RateLimit)}
+
+// tokenIdentity returns a stable, non-reversible key for a token.
+func tokenIdentity(token string) string {
+	if token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (t *rateTracker) get(identity, resource string) (RateLimit, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit, ok := t.limits[identity][resource]
+	return limit, ok
+}
+
+func (t *rateTracker) all(identity string) []RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]RateLimit, 0, len(t.limits[identity]))
+	for _, limit := range t.limits[identity] {
+		result = append(result, limit)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Resource < result[j].Resource
+	})
+	return result
+}
+
+func (t *rateTracker) update(identity, resource string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	if r := header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	limit := RateLimit{
+		Resource:  resource,
+		Remaining: remaining,
+		UpdatedAt: time.Now(),
+	}
+	limit.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	limit.Used, _ = strconv.Atoi(header.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		limit.Reset = time.Unix(reset, 0)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limits[identity] == nil {
+		t.limits[identity] = make(map[string]RateLimit)
+	}
+	t.limits[identity][resource] = limit
+}
+
+// remaining returns the usable budget, or -1 when nothing is known yet or
+// the last known window has already reset.
+func (t *rateTracker) remaining(identity, resource string) int {
+	limit, ok := t.get(identity, resource)
+	if !ok || (!limit.Reset.IsZero() && time.Now().After(limit.Reset)) {
+		return -1
+	}
+	return limit.Remaining
+}
+
+// RateLimit returns the last known budget for a resource.
+func (c *Client) RateLimit(resource string) (RateLimit, bool) {
+	return rateLimits.get(tokenIdentity(c.token), resource)
+}
+
+// RateLimits returns all known budgets for the client's token.
+func (c *Client) RateLimits() []RateLimit {
+	return rateLimits.all(tokenIdentity(c.token))
+}
+
+// CanAfford reports whether the remaining budget covers the given number of
+// calls while leaving a reserve for interactive requests. Unknown budgets are
+// assumed to be sufficient.
+func (c *Client) CanAfford(resource string, calls int) bool {
+	remaining := rateLimits.remaining(tokenIdentity(c.token), resource)
+	return remaining < 0 || remaining >= calls+fanOutReserve
+}
+
+// budgetError describes why CanAfford refused a fan-out.
+func (c *Client) budgetError(resource string) error {
+	limit, _ := c.RateLimit(resource)
+	return &RateLimitError{Resource: resource, Reset: limit.Reset}
+}
+
+// waitForBudget blocks until an exhausted budget resets if that happens
+// soon, and fails otherwise.
+func (c *Client) waitForBudget(ctx context.Context, resource string) error {
+	return c.waitForBudgetWithin(ctx, resource, maxRateLimitWait)
+}
+
+// waitForBudgetWithin is waitForBudget waiting up to maxWait.
+func (c *Client) waitForBudgetWithin(ctx context.Context, resource string, maxWait time.Duration) error {
+	limit, ok := c.RateLimit(resource)
+	if !ok || limit.Remaining > 0 {
+		return nil
+	}
+	wait := time.Until(limit.Reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxWait {
+		return &RateLimitError{Resource: resource, Reset: limit.Reset}
+	}
+	return sleep(ctx, wait)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryDelay decides whether a throttled response should be retried and how
+// long to wait first.
+func retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return min(time.Duration(seconds)*time.Second, maxRetryAfter), true
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		wait := time.Until(time.Unix(reset, 0))
+		if wait > maxRateLimitWait {
+			return 0, false
+		}
+		return max(wait, 0), true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryBackoff << attempt, true
+	}
+	return 0, false
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_TracksRateLimitHeaders(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Used", "679")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		json.NewEncoder(w).Encode(map[string]any{"login": "testuser"})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	client := NewClient("ghp_tracked")
+	if _, err := client.GetProfile(context.Background(), "testuser"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	limit, ok := client.RateLimit(ResourceCore)
+	if !ok {
+		t.Fatal("expected core rate limit to be tracked")
+	}
+	if limit.Remaining != 4321 {
+		t.Errorf("expected remaining 4321, got %d", limit.Remaining)
+	}
+	if limit.Limit != 5000 {
+		t.Errorf("expected limit 5000, got %d", limit.Limit)
+	}
+	if limit.Reset.Unix() != reset {
+		t.Errorf("expected reset %d, got %d", reset, limit.Reset.Unix())
+	}
+
+	if _, ok := NewClient("ghp_other").RateLimit(ResourceCore); ok {
+		t.Error("expected budgets to be tracked per token")
+	}
+}
+
+func TestClient_RetriesAfterSecondaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"login": "testuser"})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	profile, err := NewClient("ghp_secondary").GetProfile(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Login != "testuser" {
+		t.Errorf("expected login testuser, got %s", profile.Login)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestClient_FailsFastWhenBudgetExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"login": "testuser"})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	client := NewClient("ghp_exhausted")
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	rateLimits.update(tokenIdentity(client.token), ResourceCore, header)
+
+	if _, err := client.GetProfile(context.Background(), "testuser"); err == nil {
+		t.Fatal("expected error when budget is exhausted")
+	}
+	if calls.Load() != 0 {
+		t.Errorf("expected no upstream calls, got %d", calls.Load())
+	}
+}
+
+func TestClient_CanAfford(t *testing.T) {
+	client := NewClient("ghp_afford")
+
+	if !client.CanAfford(ResourceCore, 1000) {
+		t.Error("expected unknown budget to be affordable")
+	}
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "150")
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	rateLimits.update(tokenIdentity(client.token), ResourceCore, header)
+
+	if !client.CanAfford(ResourceCore, 20) {
+		t.Error("expected 20 calls to be affordable with 150 remaining")
+	}
+	if client.CanAfford(ResourceCore, 100) {
+		t.Error("expected 100 calls to exceed the budget after the reserve")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		headers   map[string]string
+		wantRetry bool
+	}{
+		{"ok response", http.StatusOK, nil, false},
+		{"retry after", http.StatusForbidden, map[string]string{"Retry-After": "3"}, true},
+		{"plain forbidden", http.StatusForbidden, nil, false},
+		{"too many requests", http.StatusTooManyRequests, nil, true},
+		{"primary reset far away", http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			if _, retry := retryDelay(resp, 0); retry != tt.wantRetry {
+				t.Errorf("retryDelay() retry = %v, want %v", retry, tt.wantRetry)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Repository affiliations, as used by the REST affiliation parameter.
+const (
+	AffiliationOwner              = "owner"
+	AffiliationCollaborator       = "collaborator"
+	AffiliationOrganizationMember = "organization_member"
+)
+
+// maxAuthorshipChecks bounds the repositories checked for authored commits
+// when attributing repositories the user does not own.
+const maxAuthorshipChecks = 50
+
+// RepoOptions selects the repositories stats are computed over. The zero
+// value, apart from Visibility, is the user's own non-fork, non-archived
+// repositories.
+type RepoOptions struct {
+	Visibility      string
+	Affiliations    []string
+	IncludeForks    bool
+	IncludeArchived bool
+}
+
+// ParseAffiliations parses a comma-separated affiliation list such as
+// "owner,organization_member".
+func ParseAffiliations(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var affiliations []string
+	for _, part := range strings.Split(s, ",") {
+		a := strings.TrimSpace(strings.ToLower(part))
+		switch a {
+		case AffiliationOwner, AffiliationCollaborator, AffiliationOrganizationMember:
+		default:
+			return nil, fmt.Errorf("unknown affiliation %q", part)
+		}
+		if !seen[a] {
+			seen[a] = true
+			affiliations = append(affiliations, a)
+		}
+	}
+	sort.Strings(affiliations)
+	return affiliations, nil
+}
+
+func (o RepoOptions) has(affiliation string) bool {
+	if len(o.Affiliations) == 0 {
+		return affiliation == AffiliationOwner
+	}
+	for _, a := range o.Affiliations {
+		if a == affiliation {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedOnly reports whether only the user's own repositories are selected.
+func (o RepoOptions) ownedOnly() bool {
+	return !o.has(AffiliationCollaborator) && !o.has(AffiliationOrganizationMember)
+}
+
+// Key distinguishes stats computed with these options in cache keys. It is
+// empty for the default options so existing keys stay valid.
+func (o RepoOptions) Key() string {
+	var parts []string
+	if !(len(o.Affiliations) == 0 || len(o.Affiliations) == 1 && o.Affiliations[0] == AffiliationOwner) {
+		parts = append(parts, "aff="+strings.Join(o.Affiliations, ","))
+	}
+	if o.IncludeForks {
+		parts = append(parts, "forks")
+	}
+	if o.IncludeArchived {
+		parts = append(parts, "archived")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(parts, ":")
+}
+
+func (o RepoOptions) keep(r Repository) bool {
+	if r.Fork && !o.IncludeForks {
+		return false
+	}
+	if r.Archived && !o.IncludeArchived {
+		return false
+	}
+	if o.Visibility == "private" && !r.Private {
+		return false
+	}
+	if (o.Visibility == "public" || o.Visibility == "") && r.Private {
+		return false
+	}
+	return true
+}
+
+// Owner returns the login of the repository owner, or fallback when the
+// repository was listed without its full name.
+func (r Repository) Owner(fallback string) string {
+	if owner, _, ok := strings.Cut(r.FullName, "/"); ok {
+		return owner
+	}
+	return fallback
+}
+
+// Key is the name commits of the repository are attributed to: the plain
+// name for the user's own repositories and "owner/name" for others, so
+// same-named repositories of different owners stay apart.
+func (r Repository) Key() string {
+	if r.Affiliation != "" && r.FullName != "" {
+		return r.FullName
+	}
+	return r.Name
+}
+
+// GetRepositoriesWithOptions lists the repositories selected by opts. Only
+// repositories the user actually contributed to are attributed to them when
+// other owners' repositories are included.
+func (c *Client) GetRepositoriesWithOptions(ctx context.Context, username string, opts RepoOptions) ([]Repository, error) {
+	var repos []Repository
+	if opts.has(AffiliationOwner) {
+		owned, err := c.listRepositories(ctx, username, opts, AffiliationOwner)
+		if err != nil {
+			return owned, err
+		}
+		repos = append(repos, owned...)
+	}
+	if opts.ownedOnly() {
+		return repos, nil
+	}
+
+	others, err := c.getContributedRepositories(ctx, username, opts)
+	if err != nil {
+		return repos, err
+	}
+	return append(repos, others...), nil
+}
+
+// listedRepository is a REST repository together with its owner, which
+// tells collaborator repositories from organization ones.
+type listedRepository struct {
+	Repository
+	Owner struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	} `json:"owner"`
+}
+
+func affiliationOf(ownerIsOrganization bool) string {
+	if ownerIsOrganization {
+		return AffiliationOrganizationMember
+	}
+	return AffiliationCollaborator
+}
+
+// listRepositories pages through the REST repository listing. Private
+// visibilities list the authenticated user's repositories.
+func (c *Client) listRepositories(ctx context.Context, username string, opts RepoOptions, affiliation string) ([]Repository, error) {
+	var allRepos []Repository
+	page := 1
+
+	for {
+		var repos []listedRepository
+		var endpoint string
+
+		if opts.Visibility == "all" || opts.Visibility == "private" {
+			endpoint = fmt.Sprintf("/user/repos?sort=updated&per_page=100&page=%d&affiliation=%s", page, affiliation)
+			if opts.Visibility == "all" {
+				endpoint += "&visibility=all"
+			} else {
+				endpoint += "&visibility=private"
+			}
+		} else {
+			endpoint = fmt.Sprintf("/users/%s/repos?sort=updated&per_page=100&page=%d", username, page)
+		}
+
+		if err := c.request(ctx, endpoint, &repos); err != nil {
+			return allRepos, err
+		}
+
+		if len(repos) == 0 {
+			break
+		}
+
+		for _, listed := range repos {
+			r := listed.Repository
+			if !strings.EqualFold(listed.Owner.Login, username) && listed.Owner.Login != "" {
+				r.Affiliation = affiliationOf(listed.Owner.Type == "Organization")
+			}
+			if opts.keep(r) {
+				allRepos = append(allRepos, r)
+			}
+		}
+
+		if len(repos) < 100 {
+			break
+		}
+		page++
+	}
+
+	return allRepos, nil
+}
+
+// getContributedRepositories returns repositories owned by others that the
+// user contributed to, matching the collaborator and organization member
+// affiliations of opts. Candidates come from GraphQL repositoriesContributedTo
+// and, for the authenticated user, from the repositories they can access;
+// the latter are kept only if they contain a commit authored by the user.
+func (c *Client) getContributedRepositories(ctx context.Context, username string, opts RepoOptions) ([]Repository, error) {
+	contributed, err := c.getRepositoriesContributedTo(ctx, username, opts.Visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Repository)
+	for _, r := range contributed {
+		byName[strings.ToLower(r.FullName)] = r
+	}
+
+	if opts.Visibility == "all" || opts.Visibility == "private" {
+		var affiliations []string
+		for _, a := range []string{AffiliationCollaborator, AffiliationOrganizationMember} {
+			if opts.has(a) {
+				affiliations = append(affiliations, a)
+			}
+		}
+		accessible, err := c.listRepositories(ctx, username, opts, strings.Join(affiliations, ","))
+		if err != nil {
+			return nil, err
+		}
+
+		checks := 0
+		for _, r := range accessible {
+			key := strings.ToLower(r.FullName)
+			if _, ok := byName[key]; ok || strings.EqualFold(r.Owner(username), username) {
+				continue
+			}
+			if checks == maxAuthorshipChecks || !c.CanAfford(ResourceCore, 1) {
+				break
+			}
+			checks++
+			if c.hasAuthoredCommit(ctx, r, username) {
+				byName[key] = r
+			}
+		}
+	}
+
+	repos := make([]Repository, 0, len(byName))
+	for _, r := range byName {
+		if opts.has(r.Affiliation) && opts.keep(r) {
+			repos = append(repos, r)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	return repos, nil
+}
+
+// hasAuthoredCommit reports whether a repository has a commit by username.
+func (c *Client) hasAuthoredCommit(ctx context.Context, r Repository, username string) bool {
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	endpoint := fmt.Sprintf("/repos/%s/commits?per_page=1&author=%s", r.FullName, url.QueryEscape(username))
+	if err := c.request(ctx, endpoint, &commits); err != nil {
+		return false
+	}
+	return len(commits) > 0
+}
+
+// getRepositoriesContributedTo lists repositories of other owners the user
+// recently committed to or opened pull requests or issues in.
+func (c *Client) getRepositoriesContributedTo(ctx context.Context, username, visibility string) ([]Repository, error) {
+	query := `query($login: String!, $cursor: String, $privacy: RepositoryPrivacy) {
+		user(login: $login) {
+			repositoriesContributedTo(first: 100, after: $cursor, privacy: $privacy, includeUserRepositories: false,
+				contributionTypes: [COMMIT, PULL_REQUEST, PULL_REQUEST_REVIEW, ISSUE]) {
+				pageInfo { hasNextPage endCursor }
+				nodes {
+					name
+					nameWithOwner
+					description
+					url
+					stargazerCount
+					forkCount
+					isFork
+					isArchived
+					isPrivate
+					updatedAt
+					primaryLanguage { name }
+					owner { __typename }
+				}
+			}
+		}
+	}`
+
+	vars := map[string]any{"login": username}
+	if visibility == "public" || visibility == "" {
+		vars["privacy"] = "PUBLIC"
+	}
+
+	var repos []Repository
+	for {
+		var result struct {
+			Data struct {
+				User *struct {
+					RepositoriesContributedTo struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							Name            string `json:"name"`
+							NameWithOwner   string `json:"nameWithOwner"`
+							Description     string `json:"description"`
+							URL             string `json:"url"`
+							StargazerCount  int    `json:"stargazerCount"`
+							ForkCount       int    `json:"forkCount"`
+							IsFork          bool   `json:"isFork"`
+							IsArchived      bool   `json:"isArchived"`
+							IsPrivate       bool   `json:"isPrivate"`
+							UpdatedAt       string `json:"updatedAt"`
+							PrimaryLanguage *struct {
+								Name string `json:"name"`
+							} `json:"primaryLanguage"`
+							Owner struct {
+								Typename string `json:"__typename"`
+							} `json:"owner"`
+						} `json:"nodes"`
+					} `json:"repositoriesContributedTo"`
+				} `json:"user"`
+			} `json:"data"`
+		}
+
+		if err := c.graphqlWithVars(ctx, query, vars, &result); err != nil {
+			return repos, err
+		}
+		if result.Data.User == nil {
+			return nil, fmt.Errorf("user %s: %w", username, ErrNotFound)
+		}
+
+		connection := result.Data.User.RepositoriesContributedTo
+		for _, n := range connection.Nodes {
+			r := Repository{
+				Name:        n.Name,
+				FullName:    n.NameWithOwner,
+				Description: n.Description,
+				URL:         n.URL,
+				Stars:       n.StargazerCount,
+				Forks:       n.ForkCount,
+				UpdatedAt:   n.UpdatedAt,
+				Fork:        n.IsFork,
+				Archived:    n.IsArchived,
+				Private:     n.IsPrivate,
+				Affiliation: affiliationOf(n.Owner.Typename == "Organization"),
+			}
+			if n.PrimaryLanguage != nil {
+				r.Language = n.PrimaryLanguage.Name
+			}
+			repos = append(repos, r)
+		}
+
+		if !connection.PageInfo.HasNextPage || connection.PageInfo.EndCursor == "" {
+			break
+		}
+		vars["cursor"] = connection.PageInfo.EndCursor
+	}
+	return repos, nil
+}
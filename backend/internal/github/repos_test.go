@@ -0,0 +1,181 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseAffiliations(t *testing.T) {
+	got, err := ParseAffiliations("Organization_Member, owner,owner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, ",") != "organization_member,owner" {
+		t.Errorf("expected sorted, deduplicated affiliations, got %v", got)
+	}
+	if _, err := ParseAffiliations("owner,member"); err == nil {
+		t.Error("expected an error for an unknown affiliation")
+	}
+}
+
+func TestRepoOptions_Key(t *testing.T) {
+	if key := (RepoOptions{Visibility: "public"}).Key(); key != "" {
+		t.Errorf("expected empty key for default options, got %q", key)
+	}
+	if key := (RepoOptions{Affiliations: []string{AffiliationOwner}}).Key(); key != "" {
+		t.Errorf("expected empty key for owner affiliation, got %q", key)
+	}
+
+	keys := map[string]bool{}
+	for _, opts := range []RepoOptions{
+		{},
+		{IncludeForks: true},
+		{IncludeArchived: true},
+		{IncludeForks: true, IncludeArchived: true},
+		{Affiliations: []string{AffiliationCollaborator, AffiliationOwner}},
+		{Affiliations: []string{AffiliationOrganizationMember, AffiliationOwner}},
+	} {
+		key := opts.Key()
+		if keys[key] {
+			t.Errorf("duplicate key %q for %+v", key, opts)
+		}
+		keys[key] = true
+	}
+}
+
+func TestClient_GetRepositoriesWithOptions_FiltersForksAndArchived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "app", "full_name": "octocat/app", "owner": map[string]any{"login": "octocat", "type": "User"}},
+			{"name": "fork", "fork": true},
+			{"name": "old", "archived": true},
+		})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	tests := []struct {
+		opts RepoOptions
+		want string
+	}{
+		{RepoOptions{Visibility: "public"}, "app"},
+		{RepoOptions{Visibility: "public", IncludeForks: true}, "app,fork"},
+		{RepoOptions{Visibility: "public", IncludeForks: true, IncludeArchived: true}, "app,fork,old"},
+	}
+	for _, tt := range tests {
+		repos, err := NewClient("ghp_options").GetRepositoriesWithOptions(context.Background(), "octocat", tt.opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+			if repo.Affiliation != "" {
+				t.Errorf("expected no affiliation for own repo %s, got %q", repo.Name, repo.Affiliation)
+			}
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("%+v: expected %s, got %s", tt.opts, tt.want, got)
+		}
+	}
+}
+
+func TestClient_GetRepositoriesWithOptions_AttributesContributedRepos(t *testing.T) {
+	var authorChecks []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/graphql":
+			node := func(full, ownerType string) map[string]any {
+				_, name, _ := strings.Cut(full, "/")
+				return map[string]any{"name": name, "nameWithOwner": full, "owner": map[string]any{"__typename": ownerType}}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"user": map[string]any{
+				"repositoriesContributedTo": map[string]any{
+					"pageInfo": map[string]any{"hasNextPage": false},
+					"nodes":    []map[string]any{node("alice/lib", "User"), node("acme/web", "Organization")},
+				},
+			}}})
+		case r.URL.Path == "/user/repos" && r.URL.Query().Get("affiliation") == "owner":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"name": "app", "full_name": "octocat/app", "owner": map[string]any{"login": "octocat", "type": "User"}},
+			})
+		case r.URL.Path == "/user/repos":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"name": "tool", "full_name": "acme/tool", "owner": map[string]any{"login": "acme", "type": "Organization"}},
+				{"name": "notes", "full_name": "bob/notes", "owner": map[string]any{"login": "bob", "type": "User"}},
+				{"name": "lib", "full_name": "alice/lib", "owner": map[string]any{"login": "alice", "type": "User"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			authorChecks = append(authorChecks, strings.TrimPrefix(r.URL.Path, "/repos/"))
+			if r.URL.Query().Get("author") != "octocat" {
+				t.Errorf("expected commits to be filtered by author, got %s", r.URL.RawQuery)
+			}
+			if strings.HasPrefix(r.URL.Path, "/repos/acme/") {
+				json.NewEncoder(w).Encode([]map[string]any{{"sha": "abc"}})
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]any{})
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalAPI := SetAPIURL(server.URL)
+	defer SetAPIURL(originalAPI)
+	originalGraphQL := SetGraphQLURL(server.URL + "/graphql")
+	defer SetGraphQLURL(originalGraphQL)
+
+	opts := RepoOptions{
+		Visibility:   "all",
+		Affiliations: []string{AffiliationCollaborator, AffiliationOrganizationMember, AffiliationOwner},
+	}
+	repos, err := NewClient("ghp_affiliations").GetRepositoriesWithOptions(context.Background(), "octocat", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, repo := range repos {
+		got[repo.Key()] = repo.Affiliation
+	}
+	want := map[string]string{
+		"app":       "",
+		"acme/tool": AffiliationOrganizationMember,
+		"acme/web":  AffiliationOrganizationMember,
+		"alice/lib": AffiliationCollaborator,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for key, affiliation := range want {
+		if a, ok := got[key]; !ok || a != affiliation {
+			t.Errorf("expected %s with affiliation %q, got %v", key, affiliation, got)
+		}
+	}
+	// alice/lib is known from repositoriesContributedTo and needs no check.
+	if strings.Join(authorChecks, ",") != "acme/tool/commits,bob/notes/commits" {
+		t.Errorf("unexpected authorship checks: %v", authorChecks)
+	}
+
+	opts.Affiliations = []string{AffiliationOrganizationMember}
+	repos, err = NewClient("ghp_affiliations").GetRepositoriesWithOptions(context.Background(), "octocat", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, repo := range repos {
+		if repo.Affiliation != AffiliationOrganizationMember {
+			t.Errorf("expected only organization repos, got %+v", repo)
+		}
+	}
+	if len(repos) != 2 {
+		t.Errorf("expected acme/tool and acme/web, got %+v", repos)
+	}
+}
@@ -0,0 +1,178 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// searchUsersPerCountry is how many of a country's most followed users
+	// are ranked. It costs one search call per 100 users.
+	searchUsersPerCountry = 200
+	// searchProfileBatch is how many users' contributions are fetched per
+	// GraphQL query.
+	searchProfileBatch = 25
+)
+
+// SearchSource builds country rankings itself: it searches users by
+// location, takes the most followed, and ranks them by public
+// contributions in the last year. It needs a token for the GraphQL API.
+// Searches are paced to the search budget, so countries are best loaded
+// one at a time.
+type SearchSource struct {
+	client *Client
+}
+
+// preloadWorkers keeps preloading to one country at a time: concurrent
+// countries would only queue for the same search budget.
+func (s *SearchSource) preloadWorkers() int {
+	return 1
+}
+
+func NewSearchSource(token string) *SearchSource {
+	return &SearchSource{client: NewClient(token)}
+}
+
+// Countries lists the built-in countries, since any location can be
+// searched.
+func (s *SearchSource) Countries(ctx context.Context) ([]string, error) {
+	return append([]string{}, defaultCountries...), nil
+}
+
+func (s *SearchSource) CountryUsers(ctx context.Context, country string) ([]CountryUser, error) {
+	logins, err := s.searchLogins(ctx, country)
+	if err != nil {
+		return nil, err
+	}
+	if len(logins) == 0 {
+		return nil, fmt.Errorf("country %s: %w", country, ErrNotFound)
+	}
+	batches := (len(logins) + searchProfileBatch - 1) / searchProfileBatch
+	if !s.client.CanAfford(ResourceGraphQL, batches) {
+		return nil, s.client.budgetError(ResourceGraphQL)
+	}
+
+	users := make([]CountryUser, 0, len(logins))
+	for start := 0; start < len(logins); start += searchProfileBatch {
+		batch, err := s.fetchProfiles(ctx, logins[start:min(start+searchProfileBatch, len(logins))])
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, batch...)
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].PublicContributions > users[j].PublicContributions
+	})
+	return users, nil
+}
+
+// searchLogins returns the logins of a country's most followed users.
+func (s *SearchSource) searchLogins(ctx context.Context, country string) ([]string, error) {
+	location := strings.TrimSpace(strings.ReplaceAll(country, "_", " "))
+	query := url.QueryEscape(fmt.Sprintf(`location:"%s" type:user`, location))
+
+	var logins []string
+	for page := 1; len(logins) < searchUsersPerCountry; page++ {
+		var result struct {
+			Items []struct {
+				Login string `json:"login"`
+			} `json:"items"`
+		}
+		endpoint := fmt.Sprintf("/search/users?q=%s&sort=followers&per_page=100&page=%d", query, page)
+		if err := s.client.waitForBudgetWithin(ctx, ResourceSearch, maxSearchWait); err != nil {
+			return nil, err
+		}
+		if err := s.client.request(ctx, endpoint, &result); err != nil {
+			return nil, fmt.Errorf("failed to search users in %s: %w", country, err)
+		}
+		for _, item := range result.Items {
+			logins = append(logins, item.Login)
+		}
+		if len(result.Items) < 100 {
+			break
+		}
+	}
+	return logins[:min(len(logins), searchUsersPerCountry)], nil
+}
+
+type searchProfile struct {
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatarUrl"`
+	Location  string `json:"location"`
+	Followers struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"followers"`
+	ContributionsCollection struct {
+		ContributionCalendar struct {
+			TotalContributions int `json:"totalContributions"`
+		} `json:"contributionCalendar"`
+		RestrictedContributionsCount int `json:"restrictedContributionsCount"`
+	} `json:"contributionsCollection"`
+}
+
+// fetchProfiles loads the profiles and contribution counts of logins in one
+// query with an aliased user field (u0, u1, ...) per login. Users whose
+// field failed, such as renamed or suspended accounts, are skipped; only
+// errors not tied to a user fail the batch.
+func (s *SearchSource) fetchProfiles(ctx context.Context, logins []string) ([]CountryUser, error) {
+	var params, fields strings.Builder
+	vars := make(map[string]any, len(logins))
+	for i, login := range logins {
+		fmt.Fprintf(&params, "$l%d: String!,", i)
+		fmt.Fprintf(&fields, `u%d: user(login: $l%d) {
+			login name avatarUrl location
+			followers { totalCount }
+			contributionsCollection {
+				contributionCalendar { totalContributions }
+				restrictedContributionsCount
+			}
+		}
+		`, i, i)
+		vars[fmt.Sprintf("l%d", i)] = login
+	}
+	query := fmt.Sprintf("query(%s) {\n%s}", strings.TrimSuffix(params.String(), ","), fields.String())
+
+	var result struct {
+		Data map[string]*searchProfile `json:"data"`
+	}
+	errs, err := s.client.graphqlPartial(ctx, query, vars, &result)
+	if err != nil {
+		return nil, err
+	}
+	for i := range errs {
+		var alias string
+		if path := errs[i].Path; len(path) > 0 {
+			alias, _ = path[0].(string)
+		}
+		if _, ok := result.Data[alias]; !ok {
+			return nil, &errs[i]
+		}
+		log.Printf("Warning: skipping ranking user %v: %v", vars["l"+strings.TrimPrefix(alias, "u")], &errs[i])
+	}
+
+	users := make([]CountryUser, 0, len(logins))
+	for i := range logins {
+		p := result.Data[fmt.Sprintf("u%d", i)]
+		if p == nil {
+			continue
+		}
+		total := p.ContributionsCollection.ContributionCalendar.TotalContributions
+		private := p.ContributionsCollection.RestrictedContributionsCount
+		users = append(users, CountryUser{
+			Login:                p.Login,
+			Name:                 p.Name,
+			AvatarURL:            p.AvatarURL,
+			Location:             p.Location,
+			Followers:            p.Followers.TotalCount,
+			PublicContributions:  total - private,
+			PrivateContributions: private,
+		})
+	}
+	return users, nil
+}
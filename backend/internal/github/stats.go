@@ -1,10 +1,12 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/url"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -58,75 +60,31 @@ var defaultLanguageColors = map[string]string{
 	"TeX":         "#3D6117",
 }
 
-func (c *Client) GetProfile(username string) (*Profile, error) {
+func (c *Client) GetProfile(ctx context.Context, username string) (*Profile, error) {
 	var profile Profile
 	endpoint := "/users/" + username
 	if username == "" {
 		endpoint = "/user"
 	}
-	if err := c.request(endpoint, &profile); err != nil {
+	if err := c.request(ctx, endpoint, &profile); err != nil {
 		return nil, err
 	}
 	return &profile, nil
 }
 
-func (c *Client) GetRepositories(username string) ([]Repository, error) {
-	return c.GetRepositoriesWithVisibility(username, "public")
+func (c *Client) GetRepositories(ctx context.Context, username string) ([]Repository, error) {
+	return c.GetRepositoriesWithVisibility(ctx, username, "public")
 }
 
-func (c *Client) GetRepositoriesWithVisibility(username string, visibility string) ([]Repository, error) {
-	var allRepos []Repository
-	page := 1
-
-	for {
-		var repos []Repository
-		var endpoint string
-
-		if visibility == "all" || visibility == "private" {
-			endpoint = fmt.Sprintf("/user/repos?sort=updated&per_page=100&page=%d&affiliation=owner", page)
-			if visibility == "all" {
-				endpoint += "&visibility=all"
-			} else {
-				endpoint += "&visibility=private"
-			}
-		} else {
-			endpoint = fmt.Sprintf("/users/%s/repos?sort=updated&per_page=100&page=%d", username, page)
-		}
-
-		if err := c.request(endpoint, &repos); err != nil {
-			return allRepos, err
-		}
-
-		if len(repos) == 0 {
-			break
-		}
-
-		for _, r := range repos {
-			if !r.Fork && !r.Archived {
-				if visibility == "private" && !r.Private {
-					continue
-				}
-				if visibility == "public" && r.Private {
-					continue
-				}
-				allRepos = append(allRepos, r)
-			}
-		}
-
-		if len(repos) < 100 {
-			break
-		}
-		page++
-	}
-
-	return allRepos, nil
+func (c *Client) GetRepositoriesWithVisibility(ctx context.Context, username string, visibility string) ([]Repository, error) {
+	return c.GetRepositoriesWithOptions(ctx, username, RepoOptions{Visibility: visibility})
 }
 
-func (c *Client) GetContributions(username string) ([]ContributionWeek, int, error) {
-	return c.GetContributionsForYear(username, 0)
+func (c *Client) GetContributions(ctx context.Context, username string) ([]ContributionWeek, int, error) {
+	return c.GetContributionsForYear(ctx, username, 0)
 }
 
-func (c *Client) GetContributionsForYear(username string, year int) ([]ContributionWeek, int, error) {
+func (c *Client) GetContributionsForYear(ctx context.Context, username string, year int) ([]ContributionWeek, int, error) {
 	var dateRange string
 	if year > 0 {
 		dateRange = fmt.Sprintf(`(from: "%d-01-01T00:00:00Z", to: "%d-12-31T23:59:59Z")`, year, year)
@@ -168,7 +126,7 @@ func (c *Client) GetContributionsForYear(username string, year int) ([]Contribut
 		} `json:"data"`
 	}
 
-	if err := c.graphql(query, &result); err != nil {
+	if err := c.graphql(ctx, query, &result); err != nil {
 		return nil, 0, err
 	}
 
@@ -204,15 +162,61 @@ func levelToNumber(level string) int {
 	return 0
 }
 
-func (c *Client) GetCommits(username, repo, branch string) ([]Commit, error) {
+func (c *Client) GetCommits(ctx context.Context, username, repo, branch string) ([]Commit, error) {
+	params := ""
+	if branch != "" {
+		params = "&sha=" + url.QueryEscape(branch)
+	}
+	return c.listCommits(ctx, username, repo, params)
+}
+
+// GetAuthoredCommits returns the commits in a repository that filter
+// attributes to the user. Authorship is filtered server-side with author=
+// unless co-authored commits are requested, which are only visible in the
+// commit message.
+func (c *Client) GetAuthoredCommits(ctx context.Context, owner, repo string, filter CommitFilter) ([]Commit, error) {
+	since := filter.sinceParam()
+	if filter.IsZero() {
+		return c.listCommits(ctx, owner, repo, since)
+	}
+
+	if filter.IncludeCoAuthored {
+		commits, err := c.listCommits(ctx, owner, repo, since)
+		matched := commits[:0]
+		for _, commit := range commits {
+			if filter.Matches(commit) {
+				commit.CoAuthored = !strings.EqualFold(commit.AuthorLogin, filter.Login) && !filter.ownsEmail(commit.Email)
+				matched = append(matched, commit)
+			}
+		}
+		return matched, err
+	}
+
+	seen := make(map[string]bool)
+	var commits []Commit
+	for _, author := range filter.authors() {
+		page, err := c.listCommits(ctx, owner, repo, "&author="+url.QueryEscape(author)+since)
+		for _, commit := range page {
+			if !seen[commit.SHA] {
+				seen[commit.SHA] = true
+				commits = append(commits, commit)
+			}
+		}
+		if err != nil {
+			return commits, err
+		}
+	}
+	return commits, nil
+}
+
+// listCommits pages through the commits of a repository. params is appended
+// to the query string and must start with "&".
+func (c *Client) listCommits(ctx context.Context, owner, repo, params string) ([]Commit, error) {
 	var allCommits []Commit
 	page := 1
 
 	for {
-		endpoint := fmt.Sprintf("/repos/%s/%s/commits?per_page=100&page=%d", username, repo, page)
-		if branch != "" {
-			endpoint += "&sha=" + branch
-		}
+		endpoint := fmt.Sprintf("/repos/%s/%s/commits?per_page=100&page=%d%s", owner, repo, page, params)
 
 		var response []struct {
 			SHA    string `json:"sha"`
@@ -224,10 +228,13 @@ func (c *Client) GetCommits(username, repo, branch string) ([]Commit, error) {
 					Date  string `json:"date"`
 				} `json:"author"`
 			} `json:"commit"`
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
 			HTMLURL string `json:"html_url"`
 		}
 
-		if err := c.request(endpoint, &response); err != nil {
+		if err := c.request(ctx, endpoint, &response); err != nil {
 			return allCommits, err
 		}
 
@@ -237,7 +244,7 @@ func (c *Client) GetCommits(username, repo, branch string) ([]Commit, error) {
 
 		for _, r := range response {
 			date, _ := time.Parse(time.RFC3339, r.Commit.Author.Date)
-			allCommits = append(allCommits, Commit{
+			commit := Commit{
 				SHA:     r.SHA,
 				Message: r.Commit.Message,
 				Author:  r.Commit.Author.Name,
@@ -245,7 +252,11 @@ func (c *Client) GetCommits(username, repo, branch string) ([]Commit, error) {
 				Date:    date,
 				URL:     r.HTMLURL,
 				Repo:    repo,
-			})
+			}
+			if r.Author != nil {
+				commit.AuthorLogin = r.Author.Login
+			}
+			allCommits = append(allCommits, commit)
 		}
 
 		if len(response) < 100 {
@@ -257,11 +268,19 @@ func (c *Client) GetCommits(username, repo, branch string) ([]Commit, error) {
 	return allCommits, nil
 }
 
-func (c *Client) GetAllCommits(username string, repos []Repository) ([]Commit, error) {
-	return c.GetAllCommitsWithLimit(username, repos, 0)
+func (c *Client) GetAllCommits(ctx context.Context, username string, repos []Repository) ([]Commit, error) {
+	return c.GetAllCommitsWithLimit(ctx, username, repos, 0)
 }
 
-func (c *Client) GetAllCommitsWithLimit(username string, repos []Repository, limit int) ([]Commit, error) {
+func (c *Client) GetAllCommitsWithLimit(ctx context.Context, username string, repos []Repository, limit int) ([]Commit, error) {
+	return c.GetAllAuthoredCommits(ctx, username, repos, limit, CommitFilter{})
+}
+
+// GetAllAuthoredCommits crawls the commits filter attributes to the user in
+// up to limit of the most starred repos (all of them if limit is 0). With a
+// token the GraphQL history crawler is used, which needs a few requests
+// instead of one per page of every repo; REST paging is the fallback.
+func (c *Client) GetAllAuthoredCommits(ctx context.Context, username string, repos []Repository, limit int, filter CommitFilter) ([]Commit, error) {
 	if len(repos) == 0 {
 		return []Commit{}, nil
 	}
@@ -276,6 +295,24 @@ func (c *Client) GetAllCommitsWithLimit(username string, repos []Repository, lim
 		sortedRepos = sortedRepos[:limit]
 	}
 
+	if c.canCrawlHistory(filter) {
+		commits, err := c.GetCommitHistory(ctx, username, sortedRepos, filter)
+		if err == nil {
+			sort.Slice(commits, func(i, j int) bool {
+				return commits[i].Date.After(commits[j].Date)
+			})
+			return commits, nil
+		}
+		if !isFallbackError(err) {
+			return nil, err
+		}
+		log.Printf("Warning: GraphQL commit history failed for %s, falling back to REST: %v", username, err)
+	}
+
+	if !c.CanAfford(ResourceCore, len(sortedRepos)*filter.requestsPerRepo()) {
+		return nil, c.budgetError(ResourceCore)
+	}
+
 	const maxWorkers = 10
 	numWorkers := min(maxWorkers, len(sortedRepos))
 
@@ -293,7 +330,16 @@ func (c *Client) GetAllCommitsWithLimit(username string, repos []Repository, lim
 		go func() {
 			defer wg.Done()
 			for repo := range repoChan {
-				commits, err := c.GetCommits(username, repo.Name, "")
+				if ctx.Err() != nil {
+					resultChan <- result{err: ctx.Err()}
+					continue
+				}
+				commits, err := c.GetAuthoredCommits(ctx, repo.Owner(username), repo.Name, filter)
+				if key := repo.Key(); key != repo.Name {
+					for i := range commits {
+						commits[i].Repo = key
+					}
+				}
 				resultChan <- result{commits: commits, err: err}
 			}
 		}()
@@ -317,6 +363,10 @@ func (c *Client) GetAllCommitsWithLimit(username string, repos []Repository, lim
 		allCommits = append(allCommits, res.commits...)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	sort.Slice(allCommits, func(i, j int) bool {
 		return allCommits[i].Date.After(allCommits[j].Date)
 	})
@@ -324,17 +374,22 @@ func (c *Client) GetAllCommitsWithLimit(username string, repos []Repository, lim
 	return allCommits, nil
 }
 
-func (c *Client) GetStats(username string) (*Stats, error) {
-	return c.GetStatsWithVisibility(username, "public")
+func (c *Client) GetStats(ctx context.Context, username string) (*Stats, error) {
+	return c.GetStatsWithVisibility(ctx, username, "public")
 }
 
-func (c *Client) GetStatsWithVisibility(username string, visibility string) (*Stats, error) {
-	profile, err := c.GetProfile(username)
+func (c *Client) GetStatsWithVisibility(ctx context.Context, username string, visibility string) (*Stats, error) {
+	return c.GetStatsWithOptions(ctx, username, RepoOptions{Visibility: visibility})
+}
+
+// GetStatsWithOptions computes stats over the repositories selected by opts.
+func (c *Client) GetStatsWithOptions(ctx context.Context, username string, opts RepoOptions) (*Stats, error) {
+	profile, err := c.GetProfile(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
 	}
 
-	repos, err := c.GetRepositoriesWithVisibility(username, visibility)
+	repos, err := c.GetRepositoriesWithOptions(ctx, username, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repositories: %w", err)
 	}
@@ -342,7 +397,7 @@ func (c *Client) GetStatsWithVisibility(username string, visibility string) (*St
 		repos = []Repository{}
 	}
 
-	contributions, total, err := c.GetContributions(username)
+	contributions, total, err := c.GetContributions(ctx, username)
 	if err != nil {
 		log.Printf("get contributions error for %s: %v", username, err)
 		contributions = []ContributionWeek{}
@@ -352,78 +407,53 @@ func (c *Client) GetStatsWithVisibility(username string, visibility string) (*St
 		contributions = []ContributionWeek{}
 	}
 
-	languages := c.CalculateLanguages(username, repos)
+	// One query provides both the byte totals and the colors of the
+	// languages counted by repo; without it repo counts use known colors.
+	sizes, colors, err := c.languageSizes(ctx, username, repos)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("get language sizes error for %s: %v", username, err)
+	}
+	languages := CountLanguages(repos, colors)
 	if languages == nil {
 		languages = []LanguageStats{}
 	}
+	var languagesByBytes []LanguageStats
+	if err == nil && sizes != nil {
+		languagesByBytes = languagesBySize(sizes, colors)
+	}
 	streak := calculateStreak(contributions, total)
-
-	return &Stats{
-		Profile:       *profile,
-		Repositories:  repos,
-		Contributions: contributions,
-		Languages:     languages,
-		Streak:        streak,
-		UpdatedAt:     time.Now(),
-	}, nil
-}
-
-func (c *Client) GetLanguagesWithColors(username string) (map[string]string, error) {
-	query := fmt.Sprintf(`{
-		user(login: "%s") {
-			repositories(first: 100, ownerAffiliations: OWNER) {
-				nodes {
-					languages(first: 10) {
-						edges {
-							node {
-								name
-								color
-							}
-							size
-						}
-					}
-				}
-			}
-		}
-	}`, username)
-
-	var result struct {
-		Data struct {
-			User struct {
-				Repositories struct {
-					Nodes []struct {
-						Languages struct {
-							Edges []struct {
-								Node struct {
-									Name  string `json:"name"`
-									Color string `json:"color"`
-								} `json:"node"`
-								Size int `json:"size"`
-							} `json:"edges"`
-						} `json:"languages"`
-					} `json:"nodes"`
-				} `json:"repositories"`
-			} `json:"user"`
-		} `json:"data"`
+	// The calendar above covers only the last year; streaks come from the
+	// lifetime calendar so earlier and year-spanning streaks count. It
+	// reuses the calendar above for the current year.
+	var recent []ContributionWeek
+	if len(contributions) > 0 {
+		recent = contributions
 	}
-
-	if err := c.graphql(query, &result); err != nil {
-		return nil, err
+	if lifetime, err := c.lifetimeContributions(ctx, username, recent); err == nil {
+		streak.CurrentStreak = lifetime.Streak.CurrentStreak
+		streak.LongestStreak = lifetime.Streak.LongestStreak
+	} else if ctx.Err() == nil {
+		log.Printf("get lifetime contributions error for %s: %v", username, err)
 	}
 
-	colors := make(map[string]string)
-	for _, repo := range result.Data.User.Repositories.Nodes {
-		for _, edge := range repo.Languages.Edges {
-			if edge.Node.Color != "" {
-				colors[edge.Node.Name] = edge.Node.Color
-			}
-		}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	return colors, nil
+	return &Stats{
+		Profile:          *profile,
+		Repositories:     repos,
+		Contributions:    contributions,
+		Languages:        languages,
+		Streak:           streak,
+		LanguagesByBytes: languagesByBytes,
+		UpdatedAt:        time.Now(),
+	}, nil
 }
 
-func (c *Client) CalculateLanguages(username string, repos []Repository) []LanguageStats {
+// CountLanguages weighs languages by the number of repos using them as their
+// primary language. Languages missing from colors get their usual color.
+func CountLanguages(repos []Repository, colors map[string]string) []LanguageStats {
 	langCount := make(map[string]int)
 	total := 0
 
@@ -438,23 +468,15 @@ func (c *Client) CalculateLanguages(username string, repos []Repository) []Langu
 		return nil
 	}
 
-	colors, _ := c.GetLanguagesWithColors(username)
-	if colors == nil {
-		colors = make(map[string]string)
-	}
-
 	var stats []LanguageStats
 	for name, count := range langCount {
 		color := colors[name]
 		if color == "" {
-			color = defaultLanguageColors[name]
-		}
-		if color == "" {
-			color = "#8b8b8b"
+			color = LanguageColor(name)
 		}
 		stats = append(stats, LanguageStats{
 			Name:       name,
-			Percentage: (count * 100) / total,
+			Percentage: percentage(int64(count), int64(total)),
 			Color:      color,
 		})
 	}
@@ -466,40 +488,44 @@ func (c *Client) CalculateLanguages(username string, repos []Repository) []Langu
 	return stats
 }
 
-func (c *Client) SearchUsers(query string) ([]Profile, error) {
+func (c *Client) SearchUsers(ctx context.Context, query string) ([]Profile, error) {
 	var result struct {
 		Items []Profile `json:"items"`
 	}
 	endpoint := fmt.Sprintf("/search/users?q=%s&per_page=20", url.QueryEscape(query))
-	if err := c.request(endpoint, &result); err != nil {
+	if err := c.request(ctx, endpoint, &result); err != nil {
 		return nil, err
 	}
 	return result.Items, nil
 }
 
-func (c *Client) GetFollowers(username string) ([]Profile, error) {
+func (c *Client) GetFollowers(ctx context.Context, username string) ([]Profile, error) {
 	var followers []Profile
 	endpoint := fmt.Sprintf("/users/%s/followers?per_page=100", username)
-	if err := c.request(endpoint, &followers); err != nil {
+	if err := c.request(ctx, endpoint, &followers); err != nil {
 		return nil, err
 	}
 	return followers, nil
 }
 
-func (c *Client) GetFollowing(username string) ([]Profile, error) {
+func (c *Client) GetFollowing(ctx context.Context, username string) ([]Profile, error) {
 	var following []Profile
 	endpoint := fmt.Sprintf("/users/%s/following?per_page=100", username)
-	if err := c.request(endpoint, &following); err != nil {
+	if err := c.request(ctx, endpoint, &following); err != nil {
 		return nil, err
 	}
 	return following, nil
 }
 
-func (c *Client) GetCodeFrequency(username string, repos []Repository) (*CodeFrequency, error) {
+func (c *Client) GetCodeFrequency(ctx context.Context, username string, repos []Repository) (*CodeFrequency, error) {
 	if len(repos) == 0 {
 		return &CodeFrequency{Weeks: []CodeFrequencyWeek{}}, nil
 	}
 
+	if !c.CanAfford(ResourceCore, len(repos)) {
+		return nil, c.budgetError(ResourceCore)
+	}
+
 	const maxWorkers = 10
 	numWorkers := min(maxWorkers, len(repos))
 
@@ -517,9 +543,13 @@ func (c *Client) GetCodeFrequency(username string, repos []Repository) (*CodeFre
 		go func() {
 			defer wg.Done()
 			for repo := range repoChan {
+				if ctx.Err() != nil {
+					resultChan <- result{err: ctx.Err()}
+					continue
+				}
 				var data [][]int64
-				endpoint := fmt.Sprintf("/repos/%s/%s/stats/code_frequency", username, repo.Name)
-				err := c.request(endpoint, &data)
+				endpoint := fmt.Sprintf("/repos/%s/%s/stats/code_frequency", repo.Owner(username), repo.Name)
+				err := c.request(ctx, endpoint, &data)
 				resultChan <- result{data: data, err: err}
 			}
 		}()
@@ -561,6 +591,10 @@ func (c *Client) GetCodeFrequency(username string, repos []Repository) (*CodeFre
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	weeks := make([]CodeFrequencyWeek, 0, len(weeklyData))
 	for _, w := range weeklyData {
 		weeks = append(weeks, *w)
@@ -576,10 +610,26 @@ func (c *Client) GetCodeFrequency(username string, repos []Repository) (*CodeFre
 	}, nil
 }
 
+// calculateStreak computes streaks as of today in UTC, the default timezone
+// of the API.
 func calculateStreak(contributions []ContributionWeek, total int) StreakStats {
+	return StreakAt(contributions, total, time.Now().UTC())
+}
+
+// StreakAt computes streaks as of now. The date of now in its location
+// decides which days are today and yesterday, so a streak is kept until
+// midnight in the user's timezone. Days after today are ignored.
+func StreakAt(contributions []ContributionWeek, total int, now time.Time) StreakStats {
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+
 	var allDays []ContributionDay
 	for _, w := range contributions {
-		allDays = append(allDays, w.Days...)
+		for _, day := range w.Days {
+			if day.Date <= today {
+				allDays = append(allDays, day)
+			}
+		}
 	}
 
 	if len(allDays) == 0 {
@@ -590,9 +640,6 @@ func calculateStreak(contributions []ContributionWeek, total int) StreakStats {
 		return allDays[i].Date < allDays[j].Date
 	})
 
-	today := time.Now().Format("2006-01-02")
-	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
-
 	currentStreak := 0
 	longestStreak := 0
 	tempStreak := 0
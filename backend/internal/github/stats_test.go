@@ -1,6 +1,12 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -80,9 +86,9 @@ func TestCalculateStreak_ConsecutiveDays(t *testing.T) {
 }
 
 func TestCalculateStreak_CurrentStreakFromToday(t *testing.T) {
-	today := time.Now().Format("2006-01-02")
-	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
-	twoDaysAgo := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+	today := time.Now().UTC().Format("2006-01-02")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	twoDaysAgo := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
 
 	contributions := []ContributionWeek{
 		{Days: []ContributionDay{
@@ -99,9 +105,9 @@ func TestCalculateStreak_CurrentStreakFromToday(t *testing.T) {
 }
 
 func TestCalculateStreak_CurrentStreakFromYesterday(t *testing.T) {
-	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
-	twoDaysAgo := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
-	threeDaysAgo := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	twoDaysAgo := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	threeDaysAgo := time.Now().UTC().AddDate(0, 0, -3).Format("2006-01-02")
 
 	contributions := []ContributionWeek{
 		{Days: []ContributionDay{
@@ -119,9 +125,9 @@ func TestCalculateStreak_CurrentStreakFromYesterday(t *testing.T) {
 }
 
 func TestCalculateStreak_NoCurrentStreakWhenGap(t *testing.T) {
-	threeDaysAgo := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
-	fourDaysAgo := time.Now().AddDate(0, 0, -4).Format("2006-01-02")
-	fiveDaysAgo := time.Now().AddDate(0, 0, -5).Format("2006-01-02")
+	threeDaysAgo := time.Now().UTC().AddDate(0, 0, -3).Format("2006-01-02")
+	fourDaysAgo := time.Now().UTC().AddDate(0, 0, -4).Format("2006-01-02")
+	fiveDaysAgo := time.Now().UTC().AddDate(0, 0, -5).Format("2006-01-02")
 
 	contributions := []ContributionWeek{
 		{Days: []ContributionDay{
@@ -142,7 +148,7 @@ func TestCalculateStreak_NoCurrentStreakWhenGap(t *testing.T) {
 }
 
 func TestCalculateStreak_LongestStreakInPast(t *testing.T) {
-	today := time.Now().Format("2006-01-02")
+	today := time.Now().UTC().Format("2006-01-02")
 
 	contributions := []ContributionWeek{
 		{Days: []ContributionDay{
@@ -165,6 +171,28 @@ func TestCalculateStreak_LongestStreakInPast(t *testing.T) {
 	}
 }
 
+func TestCalculateStreak_UsesUTCDateWhateverTheServerZone(t *testing.T) {
+	now := time.Now().UTC()
+	// Pick a server zone whose date differs from the UTC date right now.
+	local := time.FixedZone("UTC+14", 14*60*60)
+	if now.In(local).Format("2006-01-02") == now.Format("2006-01-02") {
+		local = time.FixedZone("UTC-12", -12*60*60)
+	}
+	defer func(saved *time.Location) { time.Local = saved }(time.Local)
+	time.Local = local
+
+	contributions := []ContributionWeek{
+		{Days: []ContributionDay{
+			{Date: now.Format("2006-01-02"), Count: 1, Level: 1},
+		}},
+	}
+	streak := calculateStreak(contributions, 1)
+
+	if streak.CurrentStreak != 1 {
+		t.Errorf("expected a contribution on today's UTC date to keep the streak, got %d", streak.CurrentStreak)
+	}
+}
+
 func TestCalculateStreak_PreservesTotalContributions(t *testing.T) {
 	contributions := []ContributionWeek{
 		{Days: []ContributionDay{
@@ -219,3 +247,49 @@ func TestClient_WithToken_ReturnsNewClientWithToken(t *testing.T) {
 		t.Error("original client should not be modified")
 	}
 }
+
+func TestClient_GetAllCommitsWithLimit_StopsOnCancelledContext(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode([]any{})
+	}))
+	defer server.Close()
+
+	original := SetAPIURL(server.URL)
+	defer SetAPIURL(original)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repos := []Repository{{Name: "repo1"}, {Name: "repo2"}, {Name: "repo3"}}
+	_, err := NewClient("ghp_cancelled").GetAllCommitsWithLimit(ctx, "testuser", repos, 0)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("expected no upstream calls, got %d", calls.Load())
+	}
+}
+
+func TestStreakAt_UsesTodayInLocation(t *testing.T) {
+	// 20:00 UTC on Jan 10 is already Jan 11 in Tokyo.
+	now := time.Date(2025, 1, 10, 20, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*3600)
+	contributions := []ContributionWeek{
+		{Days: []ContributionDay{
+			{Date: "2025-01-08", Count: 1},
+			{Date: "2025-01-09", Count: 1},
+			{Date: "2025-01-10", Count: 0},
+			{Date: "2025-01-11", Count: 1},
+		}},
+	}
+
+	if got := StreakAt(contributions, 3, now).CurrentStreak; got != 0 {
+		t.Errorf("expected streak broken in UTC, got %d", got)
+	}
+	if got := StreakAt(contributions, 3, now.In(tokyo)).CurrentStreak; got != 1 {
+		t.Errorf("expected current streak 1 in Tokyo, got %d", got)
+	}
+}
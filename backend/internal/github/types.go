@@ -4,7 +4,9 @@ import "time"
 
 type Profile struct {
 	Login       string `json:"login"`
+	NodeID      string `json:"node_id,omitempty"`
 	Name        string `json:"name"`
+	Email       string `json:"email,omitempty"`
 	AvatarURL   string `json:"avatar_url"`
 	Bio         string `json:"bio"`
 	Location    string `json:"location"`
@@ -18,6 +20,7 @@ type Profile struct {
 
 type Repository struct {
 	Name        string `json:"name"`
+	FullName    string `json:"full_name,omitempty"`
 	Description string `json:"description"`
 	URL         string `json:"html_url"`
 	Stars       int    `json:"stargazers_count"`
@@ -27,16 +30,23 @@ type Repository struct {
 	Fork        bool   `json:"fork"`
 	Archived    bool   `json:"archived"`
 	Private     bool   `json:"private"`
+	// Affiliation is set for repositories owned by someone else: the
+	// collaborator or organization_member relationship they were included by.
+	Affiliation string `json:"affiliation,omitempty"`
 }
 
 type Commit struct {
-	SHA     string    `json:"sha"`
-	Message string    `json:"message"`
-	Author  string    `json:"author"`
-	Email   string    `json:"email"`
-	Date    time.Time `json:"date"`
-	URL     string    `json:"url"`
-	Repo    string    `json:"repo"`
+	SHA         string    `json:"sha"`
+	Message     string    `json:"message"`
+	Author      string    `json:"author"`
+	Email       string    `json:"email"`
+	AuthorLogin string    `json:"authorLogin,omitempty"`
+	CoAuthored  bool      `json:"coAuthored,omitempty"`
+	Additions   int       `json:"additions,omitempty"`
+	Deletions   int       `json:"deletions,omitempty"`
+	Date        time.Time `json:"date"`
+	URL         string    `json:"url"`
+	Repo        string    `json:"repo"`
 }
 
 type ContributionDay struct {
@@ -50,9 +60,10 @@ type ContributionWeek struct {
 }
 
 type LanguageStats struct {
-	Name       string `json:"name"`
-	Percentage int    `json:"percentage"`
-	Color      string `json:"color"`
+	Name       string  `json:"name"`
+	Percentage float64 `json:"percentage"`
+	Color      string  `json:"color"`
+	Bytes      int64   `json:"bytes,omitempty"`
 }
 
 type StreakStats struct {
@@ -61,13 +72,96 @@ type StreakStats struct {
 	TotalContributions int `json:"totalContributions"`
 }
 
+type YearlyContributions struct {
+	Year  int `json:"year"`
+	Total int `json:"total"`
+}
+
+// LifetimeContributions is the contribution calendar of every year a user
+// has been active, stitched together.
+type LifetimeContributions struct {
+	Contributions []ContributionWeek    `json:"contributions"`
+	Years         []YearlyContributions `json:"years"`
+	BestYear      YearlyContributions   `json:"bestYear"`
+	Streak        StreakStats           `json:"streak"`
+}
+
 type Stats struct {
 	Profile       Profile            `json:"profile"`
 	Repositories  []Repository       `json:"repositories"`
 	Contributions []ContributionWeek `json:"contributions"`
 	Languages     []LanguageStats    `json:"languages"`
-	Streak        StreakStats        `json:"streak"`
-	UpdatedAt     time.Time          `json:"updatedAt"`
+	// LanguagesByBytes weighs languages by code size instead of one vote
+	// per repository.
+	LanguagesByBytes []LanguageStats `json:"languagesByBytes,omitempty"`
+	Streak           StreakStats     `json:"streak"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
+}
+
+// CommitFile is a file changed by a commit.
+type CommitFile struct {
+	Filename  string `json:"filename"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// LanguageTrend is one series of a stacked area chart: the share of a
+// language in each month of LanguageTrends.Months.
+type LanguageTrend struct {
+	Language string    `json:"language"`
+	Color    string    `json:"color"`
+	Shares   []float64 `json:"shares"`
+	Totals   []float64 `json:"totals"`
+}
+
+type LanguageTrends struct {
+	Weight string `json:"weight"`
+	// WeightFallback is set when lines were requested but the commits carry
+	// no line counts, so Weight fell back to commits.
+	WeightFallback bool   `json:"weightFallback,omitempty"`
+	Source         string `json:"source"`
+	// Commits is how many of the user's TotalCommits the trends cover; the
+	// files source only inspects the newest commits.
+	Commits      int             `json:"commits"`
+	TotalCommits int             `json:"totalCommits"`
+	Months       []string        `json:"months"`
+	Series       []LanguageTrend `json:"series"`
+}
+
+// ContributionTotals counts a user's contributions by kind.
+type ContributionTotals struct {
+	Commits            int `json:"commits"`
+	Issues             int `json:"issues"`
+	PullRequests       int `json:"pullRequests"`
+	PullRequestReviews int `json:"pullRequestReviews"`
+}
+
+// PullRequestMetrics summarises the pull requests a user opened. Durations
+// are in hours.
+type PullRequestMetrics struct {
+	Opened                 int     `json:"opened"`
+	Merged                 int     `json:"merged"`
+	Closed                 int     `json:"closed"`
+	Open                   int     `json:"open"`
+	MergeRate              float64 `json:"mergeRate"`
+	MedianTimeToMergeHours float64 `json:"medianTimeToMergeHours"`
+	AvgTimeToMergeHours    float64 `json:"avgTimeToMergeHours"`
+	MedianFirstReviewHours float64 `json:"medianFirstReviewHours"`
+}
+
+type YearlyPullStats struct {
+	Year    int                `json:"year"`
+	Totals  ContributionTotals `json:"totals"`
+	Metrics PullRequestMetrics `json:"metrics"`
+}
+
+type PullStats struct {
+	Totals  ContributionTotals `json:"totals"`
+	Metrics PullRequestMetrics `json:"metrics"`
+	Years   []YearlyPullStats  `json:"years"`
+	// PullRequestsSampled is the number of most recent pull requests the
+	// metrics are computed from.
+	PullRequestsSampled int `json:"pullRequestsSampled"`
 }
 
 type RepoStats struct {
@@ -98,6 +192,7 @@ type FunStats struct {
 	WeekendWarriorPercent float64            `json:"weekendWarriorPercent"`
 	NightOwlPercent       float64            `json:"nightOwlPercent"`
 	EarlyBirdPercent      float64            `json:"earlyBirdPercent"`
+	Timezone              string             `json:"timezone"`
 }
 
 type UserSearchResult struct {
@@ -148,15 +243,75 @@ type CountryRanking struct {
 
 // UserRanking represents a user's ranking within their country and globally
 type UserRanking struct {
-	Username             string `json:"username"`
-	Country              string `json:"country"`
-	CountryRank          int    `json:"countryRank"`
-	CountryTotal         int    `json:"countryTotal"`
-	GlobalRank           int    `json:"globalRank"`
-	GlobalTotal          int    `json:"globalTotal"`
-	PublicContributions  int    `json:"publicContributions"`
-	PrivateContributions int    `json:"privateContributions"`
-	Followers            int    `json:"followers"`
+	Username string `json:"username"`
+	Country  string `json:"country"`
+	// Sort is the metric CountryRank and GlobalRank are by
+	Sort         RankingMetric `json:"sort"`
+	CountryRank  int           `json:"countryRank"`
+	CountryTotal int           `json:"countryTotal"`
+	GlobalRank   int           `json:"globalRank"`
+	GlobalTotal  int           `json:"globalTotal"`
+	// Ranks holds the ranks by every metric
+	Ranks                map[RankingMetric]MetricRank `json:"ranks"`
+	PublicContributions  int                          `json:"publicContributions"`
+	PrivateContributions int                          `json:"privateContributions"`
+	Followers            int                          `json:"followers"`
+	// Country rank movement by Sort, copied from Ranks. Only rankings by
+	// public contributions are recorded, so other metrics have none.
+	DayChange  *RankMovement `json:"dayChange,omitempty"`
+	WeekChange *RankMovement `json:"weekChange,omitempty"`
+}
+
+// MetricRank is a user's rank by one metric. GlobalRank is 0 until the
+// user's country is part of the global ranking. DayChange and WeekChange
+// compare the country rank with yesterday's and last week's rankings, and
+// are absent when there is no earlier ranking by the metric including the
+// user.
+type MetricRank struct {
+	CountryRank int           `json:"countryRank"`
+	GlobalRank  int           `json:"globalRank"`
+	DayChange   *RankMovement `json:"dayChange,omitempty"`
+	WeekChange  *RankMovement `json:"weekChange,omitempty"`
+}
+
+// RankMovement is how far a user moved in a country ranking since an
+// earlier snapshot of it
+type RankMovement struct {
+	Since        string `json:"since"` // Date of the earlier snapshot (YYYY-MM-DD)
+	PreviousRank int    `json:"previousRank"`
+	Change       int    `json:"change"` // Places moved up, negative when down
+}
+
+// RankMover is a user whose country rank changed
+type RankMover struct {
+	Login        string `json:"login"`
+	Name         string `json:"name"`
+	AvatarURL    string `json:"avatarUrl"`
+	Rank         int    `json:"rank"`
+	PreviousRank int    `json:"previousRank"`
+	Change       int    `json:"change"`
+}
+
+// CountryMovers lists the users who moved furthest up and down a country
+// ranking since an earlier snapshot
+type CountryMovers struct {
+	Country string      `json:"country"`
+	Since   string      `json:"since,omitempty"` // Empty when there is no earlier snapshot
+	Up      []RankMover `json:"up"`
+	Down    []RankMover `json:"down"`
+}
+
+// RankingLoadStatus reports the progress of loading every country ranking
+type RankingLoadStatus struct {
+	Running    bool       `json:"running"`
+	Total      int        `json:"total"`  // Countries in the current or last load
+	Loaded     int        `json:"loaded"` // Countries fetched so far
+	Failed     []string   `json:"failed"` // Countries that could not be fetched
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// GlobalTotal is the number of users in the global ranking, which only
+	// changes when a load finishes or a country is fetched on demand.
+	GlobalTotal int `json:"globalTotal"`
 }
 
 // CodeFrequencyWeek represents weekly code frequency data (additions/deletions)
@@ -172,3 +327,102 @@ type CodeFrequency struct {
 	TotalAdditions int                 `json:"totalAdditions"`
 	TotalDeletions int                 `json:"totalDeletions"`
 }
+
+// Organization is the public profile of a GitHub organization.
+type Organization struct {
+	Login       string `json:"login"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AvatarURL   string `json:"avatar_url"`
+	URL         string `json:"html_url"`
+	PublicRepos int    `json:"public_repos"`
+}
+
+// OrgMemberStats is a member's row in an organization leaderboard.
+type OrgMemberStats struct {
+	Login        string     `json:"login"`
+	AvatarURL    string     `json:"avatarUrl"`
+	Commits      int        `json:"commits"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	Repositories int        `json:"repositories"`
+	LastCommit   *time.Time `json:"lastCommit,omitempty"`
+}
+
+// OrgRepoActivity summarizes member activity in one organization repository.
+type OrgRepoActivity struct {
+	Name         string     `json:"name"`
+	Language     string     `json:"language"`
+	Stars        int        `json:"stars"`
+	Commits      int        `json:"commits"`
+	Contributors int        `json:"contributors"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	LastCommit   *time.Time `json:"lastCommit,omitempty"`
+}
+
+// OrgStats aggregates activity across the members and repositories of an
+// organization, or of one of its teams. Commits are those since
+// CommitsSince in the ReposSampled most starred repositories.
+type OrgStats struct {
+	Organization     Organization      `json:"organization"`
+	Team             string            `json:"team,omitempty"`
+	Members          int               `json:"members"`
+	TotalStars       int               `json:"totalStars"`
+	TotalForks       int               `json:"totalForks"`
+	TotalCommits     int               `json:"totalCommits"`
+	ReposSampled     int               `json:"reposSampled"`
+	CommitsSince     time.Time         `json:"commitsSince"`
+	Repositories     []Repository      `json:"repositories"`
+	Languages        []LanguageStats   `json:"languages"`
+	LanguagesByBytes []LanguageStats   `json:"languagesByBytes,omitempty"`
+	CodeFrequency    *CodeFrequency    `json:"codeFrequency,omitempty"`
+	FunStats         FunStats          `json:"funStats"`
+	Leaderboard      []OrgMemberStats  `json:"leaderboard"`
+	RepoActivity     []OrgRepoActivity `json:"repoActivity"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+}
+
+// ComparedUser is one user's side of a comparison.
+type ComparedUser struct {
+	Login     string          `json:"login"`
+	Name      string          `json:"name"`
+	AvatarURL string          `json:"avatar_url"`
+	Streak    StreakStats     `json:"streak"`
+	Languages []LanguageStats `json:"languages"`
+	FunStats  FunStats        `json:"funStats"`
+}
+
+// ComparisonCalendar holds contribution counts of every compared user over
+// the same dates, in the order of Comparison.Users.
+type ComparisonCalendar struct {
+	Dates  []string `json:"dates"`
+	Counts [][]int  `json:"counts"`
+}
+
+// UserPairDelta compares two users.
+type UserPairDelta struct {
+	Users           [2]string `json:"users"`
+	SharedLanguages []string  `json:"sharedLanguages"`
+	// LanguageOverlap is the Jaccard index of the users' language sets.
+	LanguageOverlap float64 `json:"languageOverlap"`
+	// HourCorrelation is the Pearson correlation of commits by hour of day,
+	// from -1 to 1.
+	HourCorrelation float64 `json:"hourCorrelation"`
+}
+
+// ComparisonDeltas names the leader of each metric and compares every pair.
+type ComparisonDeltas struct {
+	LongestStreak      string          `json:"longestStreak"`
+	CurrentStreak      string          `json:"currentStreak"`
+	TotalContributions string          `json:"totalContributions"`
+	SharedLanguages    []string        `json:"sharedLanguages"`
+	Pairs              []UserPairDelta `json:"pairs"`
+}
+
+// Comparison lines up several users' stats side by side.
+type Comparison struct {
+	Users    []ComparedUser     `json:"users"`
+	Calendar ComparisonCalendar `json:"calendar"`
+	Deltas   ComparisonDeltas   `json:"deltas"`
+}
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is a Store persisted as JSON lines, one snapshot per line.
+// Snapshots are appended as they are recorded; replaced snapshots are
+// dropped from the file when it is next opened.
+type FileStore struct {
+	*MemoryStore
+	path string
+	file *os.File
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore loads the snapshots at path, if any, and appends new ones.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	fs := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+	replaced, err := fs.load()
+	if err != nil {
+		return nil, err
+	}
+	if replaced > 0 {
+		if err := fs.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	fs.file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	return fs, nil
+}
+
+// load reads the file and returns the number of lines superseded by later
+// snapshots of the same day.
+func (fs *FileStore) load() (int, error) {
+	f, err := os.Open(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	replaced := 0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil || s.Username == "" {
+			log.Printf("Warning: skipping unreadable history line %d in %s", line, fs.path)
+			replaced++
+			continue
+		}
+		if fs.insert(s) {
+			replaced++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read history file: %w", err)
+	}
+	log.Printf("Loaded history from %s: %d users", fs.path, len(fs.users))
+	return replaced, nil
+}
+
+// compact rewrites the file with only the kept snapshots. The file is
+// replaced atomically so a crash never loses history.
+func (fs *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, series := range fs.users {
+		for _, s := range series {
+			if err := enc.Encode(s); err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fs.path)
+}
+
+func (fs *FileStore) Record(s Snapshot) error {
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, err := fs.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	fs.insert(s)
+	return nil
+}
+
+func (fs *FileStore) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.file.Close()
+}
@@ -0,0 +1,144 @@
+// Package history records snapshots of user metrics over time, so changes
+// in followers, stars or rank can be charted after the cached stats expire.
+package history
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot holds a user's key metrics at one point in time.
+type Snapshot struct {
+	Username      string    `json:"username"`
+	Time          time.Time `json:"time"`
+	Followers     int       `json:"followers"`
+	Stars         int       `json:"stars"`
+	Contributions int       `json:"contributions"`
+	CurrentStreak int       `json:"currentStreak"`
+	LongestStreak int       `json:"longestStreak"`
+	Country       string    `json:"country,omitempty"`
+	CountryRank   int       `json:"countryRank,omitempty"`
+	GlobalRank    int       `json:"globalRank,omitempty"`
+}
+
+// day is the UTC day a snapshot counts for.
+func (s Snapshot) day() string {
+	return s.Time.UTC().Format("2006-01-02")
+}
+
+// Change is the difference between the last and first snapshot of a
+// series. Rank changes are negative when the user moved up.
+type Change struct {
+	Followers     int `json:"followers"`
+	Stars         int `json:"stars"`
+	Contributions int `json:"contributions"`
+	CountryRank   int `json:"countryRank"`
+	GlobalRank    int `json:"globalRank"`
+}
+
+// Delta compares the first and last snapshot of series. Ranks only change
+// when both snapshots have one.
+func Delta(series []Snapshot) Change {
+	if len(series) < 2 {
+		return Change{}
+	}
+	first, last := series[0], series[len(series)-1]
+	change := Change{
+		Followers:     last.Followers - first.Followers,
+		Stars:         last.Stars - first.Stars,
+		Contributions: last.Contributions - first.Contributions,
+	}
+	if first.CountryRank > 0 && last.CountryRank > 0 {
+		change.CountryRank = last.CountryRank - first.CountryRank
+	}
+	if first.GlobalRank > 0 && last.GlobalRank > 0 {
+		change.GlobalRank = last.GlobalRank - first.GlobalRank
+	}
+	return change
+}
+
+// Store keeps snapshots. A user has at most one snapshot per UTC day; a
+// later snapshot of the same day replaces the earlier one.
+type Store interface {
+	Record(s Snapshot) error
+	// Series returns a user's snapshots taken at or after since, oldest first.
+	Series(username string, since time.Time) ([]Snapshot, error)
+	// Latest returns a user's most recent snapshot.
+	Latest(username string) (Snapshot, bool)
+	// Users lists every user with snapshots.
+	Users() []string
+	Close() error
+}
+
+// MemoryStore is a Store that lives only as long as the process.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	users map[string][]Snapshot
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: make(map[string][]Snapshot)}
+}
+
+func (m *MemoryStore) Record(s Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.insert(s)
+	return nil
+}
+
+// insert adds s, replacing a snapshot of the same day. It reports whether
+// one was replaced. Callers must hold m.mu.
+func (m *MemoryStore) insert(s Snapshot) bool {
+	key := strings.ToLower(s.Username)
+	series := m.users[key]
+	i := sort.Search(len(series), func(i int) bool { return series[i].day() >= s.day() })
+	if i < len(series) && series[i].day() == s.day() {
+		series[i] = s
+		return true
+	}
+	series = append(series, Snapshot{})
+	copy(series[i+1:], series[i:])
+	series[i] = s
+	m.users[key] = series
+	return false
+}
+
+func (m *MemoryStore) Series(username string, since time.Time) ([]Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	series := m.users[strings.ToLower(username)]
+	i := sort.Search(len(series), func(i int) bool { return !series[i].Time.Before(since) })
+	result := make([]Snapshot, len(series)-i)
+	copy(result, series[i:])
+	return result, nil
+}
+
+func (m *MemoryStore) Latest(username string) (Snapshot, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	series := m.users[strings.ToLower(username)]
+	if len(series) == 0 {
+		return Snapshot{}, false
+	}
+	return series[len(series)-1], true
+}
+
+func (m *MemoryStore) Users() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	users := make([]string, 0, len(m.users))
+	for _, series := range m.users {
+		users = append(users, series[len(series)-1].Username)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
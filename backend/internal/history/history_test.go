@@ -0,0 +1,173 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var day0 = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMemoryStore_ReplacesSnapshotOfSameDay(t *testing.T) {
+	store := NewMemoryStore()
+	store.Record(Snapshot{Username: "octocat", Time: day0, Followers: 1})
+	store.Record(Snapshot{Username: "octocat", Time: day0.Add(-24 * time.Hour), Followers: 0})
+	store.Record(Snapshot{Username: "Octocat", Time: day0.Add(3 * time.Hour), Followers: 2})
+
+	series, _ := store.Series("octocat", time.Time{})
+	if len(series) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(series))
+	}
+	if series[0].Followers != 0 || series[1].Followers != 2 {
+		t.Errorf("expected oldest first with the later same-day snapshot kept, got %+v", series)
+	}
+	if latest, _ := store.Latest("OCTOCAT"); latest.Followers != 2 {
+		t.Errorf("expected latest followers 2, got %d", latest.Followers)
+	}
+}
+
+func TestMemoryStore_SeriesSince(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		store.Record(Snapshot{Username: "octocat", Time: day0.AddDate(0, 0, i), Stars: i})
+	}
+
+	series, _ := store.Series("octocat", day0.AddDate(0, 0, 3))
+	if len(series) != 2 || series[0].Stars != 3 {
+		t.Errorf("expected the last 2 snapshots, got %+v", series)
+	}
+	if series, _ := store.Series("nobody", time.Time{}); len(series) != 0 {
+		t.Errorf("expected no snapshots for an unknown user, got %d", len(series))
+	}
+}
+
+func TestDelta(t *testing.T) {
+	series := []Snapshot{
+		{Followers: 10, Stars: 5, Contributions: 100, CountryRank: 50, GlobalRank: 0},
+		{Followers: 12},
+		{Followers: 15, Stars: 4, Contributions: 180, CountryRank: 40, GlobalRank: 900},
+	}
+	want := Change{Followers: 5, Stars: -1, Contributions: 80, CountryRank: -10}
+	if got := Delta(series); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := Delta(series[:1]); got != (Change{}) {
+		t.Errorf("expected no change for a single snapshot, got %+v", got)
+	}
+}
+
+func TestFileStore_ReloadsAndCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Record(Snapshot{Username: "octocat", Time: day0, Followers: 1})
+	store.Record(Snapshot{Username: "octocat", Time: day0.Add(time.Hour), Followers: 2})
+	store.Record(Snapshot{Username: "hubot", Time: day0, Followers: 7})
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	f.WriteString("not json\n")
+	f.Close()
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if users := store.Users(); strings.Join(users, ",") != "hubot,octocat" {
+		t.Errorf("expected both users, got %v", users)
+	}
+	if latest, _ := store.Latest("octocat"); latest.Followers != 2 {
+		t.Errorf("expected the later same-day snapshot, got followers %d", latest.Followers)
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected the file compacted to 2 lines, got %d", lines)
+	}
+}
+
+func TestRecorder_RecordDueSnapshotsStaleUsers(t *testing.T) {
+	store := NewMemoryStore()
+	store.Record(Snapshot{Username: "stale", Time: time.Now().Add(-2 * time.Hour)})
+	store.Record(Snapshot{Username: "fresh", Time: time.Now()})
+	store.Record(Snapshot{Username: "broken", Time: time.Now().Add(-2 * time.Hour)})
+	store.Record(Snapshot{Username: "untracked", Time: time.Now().Add(-2 * time.Hour)})
+	tracker := NewTracker(24 * time.Hour)
+	for _, username := range []string{"stale", "fresh", "broken", "new"} {
+		tracker.Track(username)
+	}
+
+	var calls []string
+	recorder := NewRecorder(store, tracker, time.Hour, func(ctx context.Context, username string) (Snapshot, error) {
+		calls = append(calls, username)
+		if username == "broken" {
+			return Snapshot{}, errors.New("boom")
+		}
+		return Snapshot{Username: username, Time: time.Now(), Followers: 42}, nil
+	})
+	recorder.snapshot = serialize(recorder.snapshot)
+	recorder.RecordDue(context.Background())
+
+	if len(calls) != 3 {
+		t.Errorf("expected the 3 tracked users without a recent snapshot snapshotted, got %v", calls)
+	}
+	if latest, _ := store.Latest("stale"); latest.Followers != 42 {
+		t.Errorf("expected a new snapshot for stale, got %+v", latest)
+	}
+	if latest, _ := store.Latest("broken"); time.Since(latest.Time) < time.Hour {
+		t.Error("expected a failed snapshot to keep the old one")
+	}
+}
+
+func TestTracker_SkipsIdleUsersAndIsBounded(t *testing.T) {
+	tracker := NewTracker(24 * time.Hour)
+	tracker.Track("octocat")
+	tracker.Track("hubot")
+	tracker.users["hubot"].viewed = time.Now().Add(-48 * time.Hour)
+
+	tracker.Viewed("untracked")
+	if users := tracker.Active(); strings.Join(users, ",") != "octocat" {
+		t.Errorf("expected only octocat active, got %v", users)
+	}
+	if tracker.Tracked("hubot") || tracker.Tracked("untracked") {
+		t.Error("expected idle and untracked users not to be tracked")
+	}
+	tracker.Viewed("HUBOT")
+	if !tracker.Tracked("hubot") {
+		t.Error("expected a view to resume tracking hubot")
+	}
+
+	for i := len(tracker.users); i < MaxTrackedUsers; i++ {
+		if err := tracker.Track(fmt.Sprintf("user%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tracker.Track("one-too-many"); err == nil {
+		t.Errorf("expected tracking more than %d users to fail", MaxTrackedUsers)
+	}
+	if err := tracker.Track("Octocat"); err != nil {
+		t.Errorf("expected tracking a tracked user again to succeed, got %v", err)
+	}
+}
+
+// serialize guards a test SnapshotFunc that is not safe for the recorder's
+// concurrent workers.
+func serialize(f SnapshotFunc) SnapshotFunc {
+	sem := make(chan struct{}, 1)
+	return func(ctx context.Context, username string) (Snapshot, error) {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		return f(ctx, username)
+	}
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// recorderWorkers bounds the users snapshotted concurrently, leaving API
+// budget for interactive requests.
+const recorderWorkers = 2
+
+// SnapshotFunc takes a fresh snapshot of a user.
+type SnapshotFunc func(ctx context.Context, username string) (Snapshot, error)
+
+// Recorder periodically snapshots the active tracked users, so their series
+// keep growing after their cached stats expire.
+type Recorder struct {
+	store    Store
+	tracker  *Tracker
+	interval time.Duration
+	snapshot SnapshotFunc
+}
+
+func NewRecorder(store Store, tracker *Tracker, interval time.Duration, snapshot SnapshotFunc) *Recorder {
+	return &Recorder{store: store, tracker: tracker, interval: interval, snapshot: snapshot}
+}
+
+// Run records snapshots until ctx is cancelled, checking every tenth of the
+// interval for users whose latest snapshot is at least interval old.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval / 10)
+	defer ticker.Stop()
+	for {
+		r.RecordDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecordDue snapshots the active tracked users whose latest snapshot is
+// due. A failed snapshot is retried on the next check.
+func (r *Recorder) RecordDue(ctx context.Context) {
+	var due []string
+	for _, username := range r.tracker.Active() {
+		if latest, ok := r.store.Latest(username); !ok || time.Since(latest.Time) >= r.interval {
+			due = append(due, username)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	userChan := make(chan string, len(due))
+	for _, username := range due {
+		userChan <- username
+	}
+	close(userChan)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(recorderWorkers, len(due)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for username := range userChan {
+				if ctx.Err() != nil {
+					return
+				}
+				s, err := r.snapshot(ctx, username)
+				if err == nil {
+					err = r.store.Record(s)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Warning: failed to snapshot %s: %v", username, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxTrackedUsers bounds how many users are snapshotted, since each costs
+// API calls with the server's token every interval.
+const MaxTrackedUsers = 100
+
+// DefaultIdle is how long tracked users are snapshotted after they were
+// last viewed.
+const DefaultIdle = 30 * 24 * time.Hour
+
+// Tracker is the set of users the recorder snapshots. Users are only ever
+// added explicitly; viewing a user never adds them. Users nobody has viewed
+// for the idle period are not snapshotted until they are viewed again.
+type Tracker struct {
+	idle time.Duration
+
+	mu    sync.Mutex
+	users map[string]*trackedUser
+}
+
+type trackedUser struct {
+	login  string
+	viewed time.Time
+}
+
+func NewTracker(idle time.Duration) *Tracker {
+	return &Tracker{idle: idle, users: make(map[string]*trackedUser)}
+}
+
+// Track adds username, counting it as viewed now. It fails once
+// MaxTrackedUsers are tracked.
+func (t *Tracker) Track(username string) error {
+	key := strings.ToLower(username)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.users[key]; ok {
+		return nil
+	}
+	if len(t.users) >= MaxTrackedUsers {
+		return fmt.Errorf("cannot track %s: at most %d users can be tracked", username, MaxTrackedUsers)
+	}
+	t.users[key] = &trackedUser{login: username, viewed: time.Now()}
+	return nil
+}
+
+// Tracked reports whether username is tracked and has been viewed within
+// the idle period.
+func (t *Tracker) Tracked(username string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.users[strings.ToLower(username)]
+	return ok && time.Since(u.viewed) < t.idle
+}
+
+// Viewed notes that someone looked at username. Untracked users are ignored.
+func (t *Tracker) Viewed(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u, ok := t.users[strings.ToLower(username)]; ok {
+		u.viewed = time.Now()
+	}
+}
+
+// Active lists the tracked users viewed within the idle period.
+func (t *Tracker) Active() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var users []string
+	for _, u := range t.users {
+		if time.Since(u.viewed) < t.idle {
+			users = append(users, u.login)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
@@ -0,0 +1,49 @@
+package og
+
+import (
+	"context"
+	"fmt"
+	"image"
+	// Avatars are served as PNG, JPEG or GIF.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxAvatarBytes bounds the avatar download.
+const maxAvatarBytes = 2 << 20
+
+var avatarClient = &http.Client{Timeout: 5 * time.Second}
+
+// FetchAvatar downloads and decodes an avatar, asking GitHub's avatar
+// service for the size it is drawn at.
+func FetchAvatar(ctx context.Context, avatarURL string) (image.Image, error) {
+	u, err := url.Parse(avatarURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("s", strconv.Itoa(avatarSize))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := avatarClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("avatar: unexpected status %d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxAvatarBytes))
+	return img, err
+}
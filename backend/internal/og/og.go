@@ -0,0 +1,252 @@
+// Package og renders Open Graph preview images of user profiles.
+package og
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gh-stats/backend/internal/github"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Image dimensions recommended for Open Graph previews.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin     = 80
+	avatarSize = 180
+	// maxLanguages is the number of languages listed.
+	maxLanguages = 5
+	// heatmapWeeks is the number of most recent weeks in the heatmap.
+	heatmapWeeks = 52
+	heatmapCell  = 14
+)
+
+var (
+	background = color.RGBA{0x0d, 0x11, 0x17, 0xff}
+	foreground = color.RGBA{0xe6, 0xed, 0xf3, 0xff}
+	muted      = color.RGBA{0x8b, 0x94, 0x9e, 0xff}
+	accent     = color.RGBA{0xf7, 0x81, 0x66, 0xff}
+	panel      = color.RGBA{0x16, 0x1b, 0x22, 0xff}
+	heat       = [5]color.RGBA{
+		{0x16, 0x1b, 0x22, 0xff},
+		{0x0e, 0x44, 0x29, 0xff},
+		{0x00, 0x6d, 0x32, 0xff},
+		{0x26, 0xa6, 0x41, 0xff},
+		{0x39, 0xd3, 0x53, 0xff},
+	}
+)
+
+// Profile is what a preview shows. Avatar may be nil, in which case the
+// first letter of the login is drawn instead.
+type Profile struct {
+	Login         string
+	Name          string
+	Avatar        image.Image
+	Streak        github.StreakStats
+	Languages     []github.LanguageStats
+	Contributions []github.ContributionWeek
+}
+
+// renderMu serializes rendering: font faces are not safe for concurrent use.
+var renderMu sync.Mutex
+
+// Render draws the preview of p as a PNG.
+func Render(p Profile) ([]byte, error) {
+	faces, err := loadFaces()
+	if err != nil {
+		return nil, err
+	}
+	renderMu.Lock()
+	defer renderMu.Unlock()
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	drawAvatar(img, p, faces)
+
+	name := p.Name
+	if name == "" {
+		name = p.Login
+	}
+	textX := margin + avatarSize + 40
+	drawText(img, faces.title, foreground, textX, margin+85, truncate(faces.title, name, Width-margin-textX))
+	drawText(img, faces.subtitle, muted, textX, margin+135, "@"+p.Login)
+
+	drawStat(img, faces, margin, 350, days(p.Streak.CurrentStreak), "current streak")
+	drawStat(img, faces, margin+340, 350, days(p.Streak.LongestStreak), "longest streak")
+	drawLanguages(img, faces, 820, 310, p.Languages)
+	drawHeatmap(img, margin, 440, p.Contributions)
+	drawText(img, faces.label, muted, Width-margin-textWidth(faces.label, "ghstats.fun"), Height-30, "ghstats.fun")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type faceSet struct {
+	title, subtitle, stat, label font.Face
+}
+
+// loadFaces parses the bundled Go fonts once.
+var loadFaces = sync.OnceValues(func() (faceSet, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return faceSet{}, err
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return faceSet{}, err
+	}
+	var faces faceSet
+	for _, f := range []struct {
+		dst  *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&faces.title, bold, 64},
+		{&faces.subtitle, regular, 34},
+		{&faces.stat, bold, 56},
+		{&faces.label, regular, 26},
+	} {
+		if *f.dst, err = opentype.NewFace(f.font, &opentype.FaceOptions{Size: f.size, DPI: 72, Hinting: font.HintingFull}); err != nil {
+			return faceSet{}, err
+		}
+	}
+	return faces, nil
+})
+
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// truncate shortens s with an ellipsis to fit within width pixels.
+func truncate(face font.Face, s string, width int) string {
+	if textWidth(face, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(face, string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func drawAvatar(dst *image.RGBA, p Profile, faces faceSet) {
+	rect := image.Rect(margin, margin, margin+avatarSize, margin+avatarSize)
+	mask := &circle{center: image.Pt(rect.Min.X+avatarSize/2, rect.Min.Y+avatarSize/2), radius: avatarSize / 2}
+
+	if p.Avatar == nil {
+		draw.DrawMask(dst, rect, image.NewUniform(panel), image.Point{}, mask, rect.Min, draw.Over)
+		initial := strings.ToUpper(string([]rune(p.Login + "?")[0]))
+		x := rect.Min.X + (avatarSize-textWidth(faces.title, initial))/2
+		drawText(dst, faces.title, muted, x, rect.Min.Y+avatarSize/2+22, initial)
+		return
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, avatarSize, avatarSize))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), p.Avatar, p.Avatar.Bounds(), xdraw.Src, nil)
+	draw.DrawMask(dst, rect, scaled, image.Point{}, mask, rect.Min, draw.Over)
+}
+
+func drawStat(dst draw.Image, faces faceSet, x, y int, value, label string) {
+	drawText(dst, faces.stat, accent, x, y, value)
+	drawText(dst, faces.label, muted, x, y+40, label)
+}
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return strconv.Itoa(n) + " days"
+}
+
+func drawLanguages(dst *image.RGBA, faces faceSet, x, y int, languages []github.LanguageStats) {
+	for i, lang := range languages {
+		if i == maxLanguages {
+			break
+		}
+		rowY := y + i*44
+		dot := image.Rect(x, rowY-20, x+20, rowY)
+		draw.DrawMask(dst, dot, image.NewUniform(parseHex(lang.Color)), image.Point{},
+			&circle{center: image.Pt(x+10, rowY-10), radius: 10}, dot.Min, draw.Over)
+		drawText(dst, faces.label, foreground, x+34, rowY, lang.Name)
+		pct := strconv.FormatFloat(lang.Percentage, 'f', -1, 64) + "%"
+		drawText(dst, faces.label, muted, Width-margin-textWidth(faces.label, pct), rowY, pct)
+	}
+}
+
+// drawHeatmap draws the most recent heatmapWeeks weeks of the calendar.
+func drawHeatmap(dst *image.RGBA, x, y int, weeks []github.ContributionWeek) {
+	if len(weeks) > heatmapWeeks {
+		weeks = weeks[len(weeks)-heatmapWeeks:]
+	}
+	for i, week := range weeks {
+		for j, day := range week.Days {
+			level := min(max(day.Level, 0), 4)
+			cell := image.Rect(x+i*heatmapCell, y+j*heatmapCell, x+(i+1)*heatmapCell-3, y+(j+1)*heatmapCell-3)
+			draw.Draw(dst, cell, image.NewUniform(heat[level]), image.Point{}, draw.Src)
+		}
+	}
+}
+
+// parseHex parses "#rrggbb", falling back to a neutral gray.
+func parseHex(s string) color.RGBA {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 6 {
+		if v, err := strconv.ParseUint(s, 16, 32); err == nil {
+			return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+		}
+	}
+	return color.RGBA{0x85, 0x85, 0x85, 0xff}
+}
+
+// circle is an anti-aliased circular alpha mask.
+type circle struct {
+	center image.Point
+	radius int
+}
+
+func (c *circle) ColorModel() color.Model { return color.AlphaModel }
+
+func (c *circle) Bounds() image.Rectangle {
+	return image.Rect(c.center.X-c.radius, c.center.Y-c.radius, c.center.X+c.radius, c.center.Y+c.radius)
+}
+
+func (c *circle) At(x, y int) color.Color {
+	dx := float64(x-c.center.X) + 0.5
+	dy := float64(y-c.center.Y) + 0.5
+	r := float64(c.radius)
+	d := dx*dx + dy*dy
+	switch {
+	case d <= (r-1)*(r-1):
+		return color.Alpha{0xff}
+	case d >= r*r:
+		return color.Alpha{0}
+	default:
+		// Fade the outermost pixel for a smooth edge.
+		return color.Alpha{uint8(255 * (r*r - d) / (r*r - (r-1)*(r-1)))}
+	}
+}
@@ -0,0 +1,69 @@
+package og
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"gh-stats/backend/internal/github"
+)
+
+func TestRender_ProducesPreviewSizedPNG(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	avatar := image.NewRGBA(image.Rect(0, 0, 460, 460))
+	draw.Draw(avatar, avatar.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+	weeks := []github.ContributionWeek{{Days: []github.ContributionDay{{Level: 4}, {Level: 0}}}}
+
+	for _, p := range []Profile{
+		{Login: "octocat", Name: "The Octocat", Avatar: avatar},
+		{Login: "octocat", Contributions: weeks, Languages: []github.LanguageStats{{Name: "Go", Color: "#00ADD8", Percentage: 61.5}}},
+	} {
+		data, err := Render(p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("invalid PNG: %v", err)
+		}
+		if img.Bounds().Dx() != Width || img.Bounds().Dy() != Height {
+			t.Errorf("expected %dx%d, got %v", Width, Height, img.Bounds())
+		}
+
+		center := img.At(margin+avatarSize/2, margin+avatarSize/2)
+		corner := img.At(margin+1, margin+1)
+		if p.Avatar != nil && center != red {
+			t.Errorf("expected the avatar in the circle, got %v", center)
+		}
+		if corner != background {
+			t.Errorf("expected the avatar corner to be masked, got %v", corner)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	if got := parseHex("#00ADD8"); got != (color.RGBA{0x00, 0xad, 0xd8, 0xff}) {
+		t.Errorf("unexpected color %v", got)
+	}
+	if got := parseHex("blue"); got.A != 0xff {
+		t.Errorf("expected an opaque fallback, got %v", got)
+	}
+}
+
+func TestTruncate_FitsWidth(t *testing.T) {
+	faces, err := loadFaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := "An extremely long display name that cannot possibly fit"
+	got := truncate(faces.title, long, 300)
+	if textWidth(faces.title, got) > 300 || got[len(got)-len("…"):] != "…" {
+		t.Errorf("expected an ellipsized name within 300px, got %q", got)
+	}
+	if truncate(faces.title, "short", 300) != "short" {
+		t.Error("expected short names to be kept")
+	}
+}
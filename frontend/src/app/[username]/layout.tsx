@@ -0,0 +1,22 @@
+import type { Metadata } from "next";
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || "";
+
+interface LayoutProps {
+  children: React.ReactNode;
+  params: Promise<{ username: string }>;
+}
+
+export async function generateMetadata({ params }: Omit<LayoutProps, "children">): Promise<Metadata> {
+  const { username } = await params;
+  const image = `${API_URL}/api/og/${encodeURIComponent(username)}.png`;
+  return {
+    title: `${username} · GitHub Stats`,
+    openGraph: { images: [{ url: image, width: 1200, height: 630 }] },
+    twitter: { card: "summary_large_image", images: [image] },
+  };
+}
+
+export default function UserLayout({ children }: LayoutProps) {
+  return children;
+}
@@ -10,6 +10,7 @@ import type {
   ContributionWeek,
   GlobalRanking,
   CodeFrequency,
+  RankingMetric,
 } from "./types";
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "";
@@ -200,8 +201,13 @@ export async function getAvailableCountries(): Promise<string[]> {
   return data.countries;
 }
 
-export async function getCountryRanking(country: string): Promise<CountryRanking> {
-  const res = await fetch(`${API_URL}/api/rankings/country/${encodeURIComponent(country)}`, {
+export async function getCountryRanking(country: string, sort?: RankingMetric): Promise<CountryRanking> {
+  let endpoint = `${API_URL}/api/rankings/country/${encodeURIComponent(country)}`;
+  if (sort) {
+    endpoint += `?sort=${sort}`;
+  }
+
+  const res = await fetch(endpoint, {
     credentials: "include",
     next: { revalidate: 3600 },
   });
@@ -214,11 +220,17 @@ export async function getCountryRanking(country: string): Promise<CountryRanking
   return res.json();
 }
 
-export async function getUserRanking(username: string, country?: string): Promise<UserRankingResult> {
-  let endpoint = `${API_URL}/api/rankings/user/${encodeURIComponent(username)}`;
-  if (country) {
-    endpoint += `?country=${encodeURIComponent(country)}`;
-  }
+export async function getUserRanking(
+  username: string,
+  country?: string,
+  sort?: RankingMetric,
+): Promise<UserRankingResult> {
+  const params = new URLSearchParams();
+  if (country) params.set("country", country);
+  if (sort) params.set("sort", sort);
+
+  const query = params.toString();
+  const endpoint = `${API_URL}/api/rankings/user/${encodeURIComponent(username)}${query ? `?${query}` : ""}`;
 
   const res = await fetch(endpoint, {
     credentials: "include",
@@ -230,9 +242,10 @@ export async function getUserRanking(username: string, country?: string): Promis
   return res.json();
 }
 
-export async function getGlobalRanking(limit?: number): Promise<GlobalRanking> {
+export async function getGlobalRanking(limit?: number, sort?: RankingMetric): Promise<GlobalRanking> {
   const params = new URLSearchParams();
   if (limit) params.set("limit", limit.toString());
+  if (sort) params.set("sort", sort);
 
   const query = params.toString();
   const endpoint = `${API_URL}/api/rankings/global${query ? `?${query}` : ""}`;
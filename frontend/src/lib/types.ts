@@ -131,16 +131,35 @@ export interface CountryRanking {
   updatedAt: string;
 }
 
+export type RankingMetric = "public" | "total" | "followers";
+
+export interface RankMovement {
+  since: string;
+  previousRank: number;
+  change: number;
+}
+
+export interface MetricRank {
+  countryRank: number;
+  globalRank: number;
+  dayChange?: RankMovement;
+  weekChange?: RankMovement;
+}
+
 export interface UserRanking {
   username: string;
   country: string;
+  sort: RankingMetric;
   countryRank: number;
   countryTotal: number;
   globalRank: number;
   globalTotal: number;
+  ranks: Record<RankingMetric, MetricRank>;
   publicContributions: number;
   privateContributions: number;
   followers: number;
+  dayChange?: RankMovement;
+  weekChange?: RankMovement;
 }
 
 export interface UserRankingResult {
@@ -152,6 +171,8 @@ export interface GlobalUser {
   login: string;
   country: string;
   publicContributions: number;
+  privateContributions: number;
+  followers: number;
 }
 
 export interface GlobalRanking {